@@ -19,6 +19,7 @@ func init() {
 	rootCmd.PersistentFlags().String("aws.session_token", "", "AWS session token")
 	rootCmd.PersistentFlags().Bool("aws.dynamodb.enabled", false, "Store measurements to AWS DynamoDB")
 	rootCmd.PersistentFlags().String("aws.dynamodb.table", "", "AWS DynamoDB table name")
+	rootCmd.PersistentFlags().String("aws.dynamodb.aggregates_table", "", "AWS DynamoDB table name for aggregates, leave empty to not store aggregates")
 	rootCmd.PersistentFlags().Bool("aws.sqs.enabled", false, "Send measurements to AWS SQS")
 	rootCmd.PersistentFlags().String("aws.sqs.queue.name", "", "AWS SQS queue name")
 	rootCmd.PersistentFlags().String("aws.sqs.queue.url", "", "AWS SQS queue URL")
@@ -31,6 +32,7 @@ func addDynamoDBExporter(exporters *[]exporter.Exporter) error {
 	}
 	exp, err := dynamodb.New(dynamodb.Config{
 		Table:           table,
+		AggregatesTable: viper.GetString("aws.dynamodb.aggregates_table"),
 		Region:          viper.GetString("aws.region"),
 		AccessKeyID:     viper.GetString("aws.access_key_id"),
 		SecretAccessKey: viper.GetString("aws.secret_access_key"),
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-ble/ble"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/calibration"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Compute calibration offsets for RuuviTags placed side by side with a reference tag",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reference := viper.GetString("calibrate.reference")
+		if reference == "" {
+			return fmt.Errorf("reference tag must be specified")
+		}
+		reference = ble.NewAddr(reference).String()
+		if len(peripherals) > 0 {
+			if _, ok := peripherals[reference]; !ok {
+				return fmt.Errorf("reference tag %s is not configured", reference)
+			}
+		}
+		est := calibration.NewEstimator(reference, viper.GetDuration("calibrate.maxage"))
+		p := scanner.New(logger, peripherals)
+		p.Scheduler = scanner.Continuous{}
+		p.Exporters = []exporter.Exporter{estimatorExporter{est}}
+		p.SetDecoders(decoders)
+		p.SetGateway(gateway)
+		if bleScanner != nil {
+			p.SetBLEScanner(bleScanner)
+		}
+		duration := viper.GetDuration("calibrate.duration")
+		logger.Info("Calibrating", zap.Duration("duration", duration))
+		ctx, cancel := context.WithTimeout(context.Background(), duration)
+		defer cancel()
+		if err := runPipeline(ctx, p); err != nil {
+			return err
+		}
+		printCalibrations(est.Results())
+		return nil
+	},
+}
+
+func init() {
+	calibrateCmd.Flags().String("calibrate.reference", "", "Address of the reference RuuviTag")
+	calibrateCmd.Flags().Duration("calibrate.duration", 30*time.Minute, "Duration of the calibration run")
+	calibrateCmd.Flags().Duration("calibrate.maxage", 10*time.Second, "Maximum time between paired measurements")
+
+	viper.BindPFlags(calibrateCmd.Flags())
+
+	rootCmd.AddCommand(calibrateCmd)
+}
+
+func printCalibrations(results map[string]calibration.Result) {
+	var addrs []string
+	for addr := range results {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	fmt.Println("ruuvitags:")
+	for _, addr := range addrs {
+		r := results[addr]
+		fmt.Printf("  %q:\n", addr)
+		fmt.Printf("    name: %s\n", peripherals[addr])
+		fmt.Printf("    # %d samples\n", r.Samples)
+		fmt.Println("    calibration:")
+		fmt.Printf("      temperature:\n        offset: %.2f\n", r.Temperature.Offset)
+		fmt.Printf("      humidity:\n        offset: %.2f\n", r.Humidity.Offset)
+		fmt.Printf("      pressure:\n        offset: %.2f\n", r.Pressure.Offset)
+	}
+}
+
+// estimatorExporter feeds measurements received during calibration to the estimator
+type estimatorExporter struct {
+	est *calibration.Estimator
+}
+
+func (e estimatorExporter) Name() string {
+	return "Calibration"
+}
+
+func (e estimatorExporter) Export(ctx context.Context, data sensor.Data) error {
+	e.est.Add(data)
+	return nil
+}
+
+func (e estimatorExporter) Close() error {
+	return nil
+}
@@ -3,8 +3,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/signal"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -16,34 +14,21 @@ var collectCmd = &cobra.Command{
 	Use:   "collect",
 	Short: "Collect measurements from all specified RuuviTags once",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(peripherals) == 0 {
+			return fmt.Errorf("at least one peripheral must be specified")
+		}
 		logger.Info("Starting ruuvitag-gollector")
-		scn := scanner.NewOnce(logger, peripherals)
-		scn.Exporters = exporters
-		return runOnce(scn)
+		logger.Info("Scanning once")
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := runPipeline(ctx, newPipeline(scanner.Once{})); err != nil {
+			return fmt.Errorf("failed to scan: %w", err)
+		}
+		logger.Info("Stopping scanner")
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(collectCmd)
 }
-
-func runOnce(scn *scanner.OnceScanner) error {
-	if err := scn.Init(device); err != nil {
-		return err
-	}
-	logger.Info("Scanning once")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-	go func() {
-		<-interrupt
-		cancel()
-	}()
-	if err := scn.Scan(ctx); err != nil {
-		return fmt.Errorf("failed to scan: %w", err)
-	}
-	logger.Info("Stopping scanner")
-	scn.Close()
-	return nil
-}
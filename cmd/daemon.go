@@ -2,12 +2,11 @@ package cmd
 
 import (
 	"context"
-	"os"
-	"os/signal"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 
 	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
 )
@@ -17,16 +16,19 @@ var daemonCmd = &cobra.Command{
 	Short: "Collect measurements from specified RuuviTags continuously",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		logger.Info("Starting ruuvitag-gollector")
-		interval := viper.GetDuration("interval")
-		if interval > 0 {
-			scn := scanner.NewInterval(logger, peripherals)
-			scn.Exporters = exporters
-			return runWithInterval(scn, interval)
+		var scheduler scanner.Scheduler = scanner.Continuous{}
+		if interval := viper.GetDuration("interval"); interval > 0 {
+			logger.Info("Scanning measurements", zap.Duration("interval", interval))
+			scheduler = scanner.Interval{Interval: interval}
 		} else {
-			scn := scanner.NewContinuous(logger, peripherals)
-			scn.Exporters = exporters
-			return runContinuously(scn)
+			logger.Info("Listening for measurements")
 		}
+		p := newPipeline(scheduler)
+		p.Aggregate = viper.GetBool("aggregate") && viper.GetDuration("interval") > 0
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go watchOffline(ctx)
+		return runPipeline(ctx, p)
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		logger.Info("Stopping ruuvitag-gollector")
@@ -35,40 +37,26 @@ var daemonCmd = &cobra.Command{
 
 func init() {
 	daemonCmd.Flags().Duration("interval", 60*time.Second, "Wait time between RuuviTag device scans, 0 to scan continuously")
+	daemonCmd.Flags().Bool("aggregate", false, "Listen for the whole interval and export statistics of the measurements of each RuuviTag")
 
 	viper.BindPFlags(daemonCmd.Flags())
 
 	rootCmd.AddCommand(daemonCmd)
 }
 
-func runWithInterval(scn *scanner.Scanner, scanInterval time.Duration) error {
-	if err := scn.Init(device); err != nil {
-		return err
+// watchOffline periodically checks for RuuviTags that have gone offline and exports the offline events
+func watchOffline(ctx context.Context) {
+	if lastSeen == nil {
+		return
 	}
-	ctx := context.Background()
-	scn.Scan(ctx, scanInterval)
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-	select {
-	case <-interrupt:
-	case <-scn.Quit:
-	}
-	scn.Stop()
-	return nil
-}
-
-func runContinuously(scn *scanner.ContinuousScanner) error {
-	if err := scn.Init(device); err != nil {
-		return err
-	}
-	ctx := context.Background()
-	scn.Scan(ctx)
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-	select {
-	case <-interrupt:
-	case <-scn.Quit:
+	ticker := time.NewTicker(lastSeen.Timeout / 10)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			scanner.ExportEvents(ctx, logger, exporters, lastSeen.Check(now))
+		case <-ctx.Done():
+			return
+		}
 	}
-	scn.Stop()
-	return nil
 }
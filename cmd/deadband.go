@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/deadband"
+)
+
+// applyDeadband wraps the most recently added exporter in a deadband filter if one is
+// configured under the key of the exporter:
+//
+//	mqtt:
+//	  deadband:
+//	    heartbeat: 5m
+//	    fields:
+//	      temperature: 0.1
+//	      humidity: 1
+//	      pressure: 0.5
+func applyDeadband(key string, exporters []exporter.Exporter) error {
+	cfgKey := key + ".deadband"
+	if !viper.IsSet(cfgKey) || len(exporters) == 0 {
+		return nil
+	}
+	thresholds := make(map[string]float64)
+	for field, v := range viper.GetStringMap(cfgKey + ".fields") {
+		threshold, err := toFloat64(v)
+		if err != nil {
+			return fmt.Errorf("invalid deadband of field %s for %s: %w", field, key, err)
+		}
+		thresholds[field] = threshold
+	}
+	i := len(exporters) - 1
+	exp, err := deadband.New(exporters[i], thresholds, viper.GetDuration(cfgKey+".heartbeat"))
+	if err != nil {
+		return fmt.Errorf("invalid deadband for %s: %w", key, err)
+	}
+	exporters[i] = exp
+	return nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func newDecoders(names []string, keys map[string][]byte) (*sensor.Registry, error) {
+	decoders := sensor.NewRegistry()
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "ruuvi":
+			decoders.Register(sensor.Ruuvi{Keys: keys})
+		case "atc", "pvvx":
+			decoders.Register(sensor.ATC{})
+		case "govee":
+			decoders.Register(sensor.Govee{})
+		case "switchbot":
+			decoders.Register(sensor.SwitchBot{})
+		default:
+			return nil, fmt.Errorf("unknown decoder: %s", name)
+		}
+	}
+	return decoders, nil
+}
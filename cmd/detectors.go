@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"expvar"
+
+	"github.com/spf13/viper"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/battery"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/lastseen"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/motion"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/rules"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+)
+
+func newDetectors() ([]scanner.Detector, error) {
+	var detectors []scanner.Detector
+	if viper.GetBool("motion.enabled") {
+		detectors = append(detectors, new(motion.Detector))
+	}
+	if threshold := viper.GetFloat64("battery.low_threshold"); threshold > 0 {
+		detectors = append(detectors, &battery.Monitor{Threshold: threshold})
+	}
+	if linkQuality != nil {
+		// The tracker records sequence numbers as a processor and reports them as a detector
+		detectors = append(detectors, linkQuality)
+	}
+	if timeout := viper.GetDuration("offline.timeout"); timeout > 0 {
+		lastSeen = lastseen.NewRegistry(timeout, peripherals)
+		expvar.Publish("last_seen", expvar.Func(func() interface{} {
+			return lastSeen.Status()
+		}))
+		detectors = append(detectors, lastSeen)
+	}
+	ruleCfg, err := parseRules(viper.Get("rules"), peripherals)
+	if err != nil {
+		return nil, err
+	}
+	if len(ruleCfg) > 0 {
+		engine, err := rules.NewEngine(ruleCfg)
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, engine)
+	}
+	return detectors, nil
+}
@@ -18,6 +18,7 @@ func init() {
 	rootCmd.PersistentFlags().String("influxdb.bucket", "", "InfluxDB bucket")
 	rootCmd.PersistentFlags().String("influxdb.database", "", "InfluxDB database (1.x)")
 	rootCmd.PersistentFlags().String("influxdb.measurement", "", "InfluxDB measurement name")
+	rootCmd.PersistentFlags().String("influxdb.aggregate_measurement", "", "InfluxDB measurement name for aggregates, defaults to the measurement name with an _aggregate suffix")
 	rootCmd.PersistentFlags().String("influxdb.token", "", "InfluxDB token")
 	rootCmd.PersistentFlags().String("influxdb.username", "", "InfluxDB username (1.x)")
 	rootCmd.PersistentFlags().String("influxdb.password", "", "InfluxDB password (1.x)")
@@ -29,14 +30,15 @@ func addInfluxDBExporter(exporters *[]exporter.Exporter) error {
 		return fmt.Errorf("InfluxDB address must be specified")
 	}
 	influx := influxdb.New(influxdb.Config{
-		Addr:        addr,
-		Org:         viper.GetString("influxdb.org"),
-		Bucket:      viper.GetString("influxdb.bucket"),
-		Database:    viper.GetString("influxdb.database"),
-		Measurement: viper.GetString("influxdb.measurement"),
-		Token:       viper.GetString("influxdb.token"),
-		Username:    viper.GetString("influxdb.username"),
-		Password:    viper.GetString("influxdb.password"),
+		Addr:                 addr,
+		Org:                  viper.GetString("influxdb.org"),
+		Bucket:               viper.GetString("influxdb.bucket"),
+		Database:             viper.GetString("influxdb.database"),
+		Measurement:          viper.GetString("influxdb.measurement"),
+		AggregateMeasurement: viper.GetString("influxdb.aggregate_measurement"),
+		Token:                viper.GetString("influxdb.token"),
+		Username:             viper.GetString("influxdb.username"),
+		Password:             viper.GetString("influxdb.password"),
 	})
 	*exporters = append(*exporters, influx)
 	return nil
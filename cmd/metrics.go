@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	_ "expvar"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// startMetricsServer serves the published metrics as JSON from /debug/vars
+func startMetricsServer(addr string) {
+	go func() {
+		logger.Info("Serving metrics", zap.String("addr", addr))
+		if err := http.ListenAndServe(addr, nil); err != nil {
+			logger.Error("Metrics server failed", zap.Error(err))
+		}
+	}()
+}
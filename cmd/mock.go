@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
 
@@ -27,7 +28,17 @@ func sendMockMeasurement() {
 	ts := time.Now()
 	var measurements []sensor.Data
 	for addr, name := range peripherals {
-		measurements = append(measurements, generateMockData(addr, name, ts))
+		data, err := generateMockData(addr, name, ts)
+		if err != nil {
+			logger.Error("Failed to generate mock measurement", zap.Error(err))
+			continue
+		}
+		data, err = scanner.Process(data, processors)
+		if err != nil {
+			logger.Error("Failed to process mock measurement", zap.Error(err))
+			continue
+		}
+		measurements = append(measurements, data)
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -38,21 +49,37 @@ func sendMockMeasurement() {
 				logger.Error("Failed to export measurement", zap.Error(err))
 			}
 		}
+		if err := exporter.Close(); err != nil {
+			logger.Error("Failed to close exporter", zap.Error(err))
+		}
 	}
 }
 
-func generateMockData(addr, name string, ts time.Time) sensor.Data {
-	return sensor.Data{
-		Addr:            addr,
-		Name:            name,
-		Temperature:     21.5,
-		Humidity:        60,
-		Pressure:        1002,
-		BatteryVoltage:  2.755,
-		AccelerationX:   0,
-		AccelerationY:   0,
-		AccelerationZ:   0,
-		MovementCounter: 0,
-		Timestamp:       ts,
+// generateMockData encodes a mock RAWv2 advertisement and decodes it like a real one
+func generateMockData(addr, name string, ts time.Time) (sensor.Data, error) {
+	payload, err := sensor.Encode(5, sensor.Data{
+		Addr:              addr,
+		Temperature:       sensor.Float64(21.5),
+		Humidity:          sensor.Float64(60),
+		Pressure:          sensor.Float64(1002),
+		BatteryVoltage:    2.755,
+		TxPower:           -16,
+		AccelerationX:     sensor.Int(0),
+		AccelerationY:     sensor.Int(0),
+		AccelerationZ:     sensor.Int(1000),
+		MovementCounter:   0,
+		MeasurementNumber: sensor.Int(0),
+	})
+	if err != nil {
+		return sensor.Data{}, err
+	}
+	data, err := sensor.Parse(payload)
+	if err != nil {
+		return sensor.Data{}, err
 	}
+	data.Addr = addr
+	data.Name = name
+	data.DewPoint = sensor.DewPoint(data.Temperature, data.Humidity)
+	data.Timestamp = ts
+	return data, nil
 }
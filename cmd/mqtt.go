@@ -10,6 +10,7 @@ import (
 
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/mqtt"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/notify"
 )
 
 func init() {
@@ -25,23 +26,39 @@ func init() {
 }
 
 func addMQTTExporter(exporters *[]exporter.Exporter) error {
+	cfg, err := mqttConfig()
+	if err != nil {
+		return err
+	}
+	exporter, err := mqtt.New(cfg)
+	if err != nil {
+		return err
+	}
+	*exporters = append(*exporters, exporter)
+	return nil
+}
+
+func newMQTTNotifier(topic string) (notify.Notifier, error) {
+	cfg, err := mqttConfig()
+	if err != nil {
+		return nil, err
+	}
+	return mqtt.NewNotifier(cfg, topic)
+}
+
+func mqttConfig() (mqtt.Config, error) {
 	addr := viper.GetString("mqtt.addr")
 	if addr == "" {
-		return fmt.Errorf("MQTT broker address must be specified")
+		return mqtt.Config{}, fmt.Errorf("MQTT broker address must be specified")
 	}
-	exporter, err := mqtt.New(mqtt.Config{
+	return mqtt.Config{
 		Addr:              addr,
-		Topic: 			   viper.GetString("mqtt.topic"),
+		Topic:             viper.GetString("mqtt.topic"),
 		ClientId:          viper.GetString("mqtt.client_id"),
 		Username:          viper.GetString("mqtt.username"),
 		Password:          viper.GetString("mqtt.password"),
 		CaFile:            viper.GetString("mqtt.ca_file"),
 		AutoReconnect:     viper.GetBool("mqtt.auto_reconnect"),
 		ReconnectInterval: time.Duration(viper.GetInt("mqtt.reconnect_interval")) * time.Second,
-	})
-	if err != nil {
-		return err
-	}
-	*exporters = append(*exporters, exporter)
-	return nil
+	}, nil
 }
@@ -2,8 +2,15 @@
 
 package cmd
 
-import "github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+import (
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/notify"
+)
 
 func addMQTTExporter(exporters *[]exporter.Exporter) error {
 	return ErrNotEnabled
 }
+
+func newMQTTNotifier(topic string) (notify.Notifier, error) {
+	return nil, ErrNotEnabled
+}
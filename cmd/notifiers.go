@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/notify"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringSlice("notify.events", []string{sensor.EventLowBattery, sensor.EventAlert, sensor.EventAlertResolved}, "Event types to send notifications of")
+	rootCmd.PersistentFlags().Bool("notify.log.enabled", false, "Write notifications to the log")
+	rootCmd.PersistentFlags().Bool("notify.webhook.enabled", false, "Post notifications as JSON to a webhook")
+	rootCmd.PersistentFlags().String("notify.webhook.url", "", "Webhook URL")
+	rootCmd.PersistentFlags().String("notify.webhook.token", "", "Webhook authorization token")
+	rootCmd.PersistentFlags().Bool("notify.smtp.enabled", false, "Send notifications by email")
+	rootCmd.PersistentFlags().String("notify.smtp.host", "", "SMTP server host")
+	rootCmd.PersistentFlags().Int("notify.smtp.port", 587, "SMTP server port")
+	rootCmd.PersistentFlags().String("notify.smtp.username", "", "SMTP username")
+	rootCmd.PersistentFlags().String("notify.smtp.password", "", "SMTP password")
+	rootCmd.PersistentFlags().String("notify.smtp.from", "", "Email sender address")
+	rootCmd.PersistentFlags().StringSlice("notify.smtp.to", nil, "Email recipient addresses")
+	rootCmd.PersistentFlags().Bool("notify.mqtt.enabled", false, "Publish notifications to a MQTT topic using the MQTT broker settings")
+	rootCmd.PersistentFlags().String("notify.mqtt.topic", "ruuvitag-gollector/notifications", "MQTT topic for notifications")
+	rootCmd.PersistentFlags().Bool("notify.command.enabled", false, "Run a command for each notification")
+	rootCmd.PersistentFlags().String("notify.command.path", "", "Command to run")
+	rootCmd.PersistentFlags().StringSlice("notify.command.args", nil, "Command arguments")
+}
+
+// addNotifiers adds the enabled notifiers as exporters receiving the configured event types
+func addNotifiers(exporters *[]exporter.Exporter) error {
+	eventTypes := make(map[string]bool)
+	for _, t := range viper.GetStringSlice("notify.events") {
+		eventTypes[t] = true
+	}
+	// Each notifier gets an export queue of its own, configured under its key
+	add := func(key string, n notify.Notifier) error {
+		*exporters = append(*exporters, notify.Exporter{Notifier: n, EventTypes: eventTypes})
+		return applyQueue(key, *exporters)
+	}
+	if viper.GetBool("notify.log.enabled") {
+		if err := add("notify.log", notify.Log{Logger: logger}); err != nil {
+			return err
+		}
+	}
+	if viper.GetBool("notify.webhook.enabled") {
+		n, err := notify.NewWebhook(viper.GetString("notify.webhook.url"), viper.GetString("notify.webhook.token"), 10*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to create webhook notifier: %w", err)
+		}
+		if err := add("notify.webhook", n); err != nil {
+			return err
+		}
+	}
+	if viper.GetBool("notify.smtp.enabled") {
+		n, err := notify.NewSMTP(notify.SMTPConfig{
+			Host:     viper.GetString("notify.smtp.host"),
+			Port:     viper.GetInt("notify.smtp.port"),
+			Username: viper.GetString("notify.smtp.username"),
+			Password: viper.GetString("notify.smtp.password"),
+			From:     viper.GetString("notify.smtp.from"),
+			To:       viper.GetStringSlice("notify.smtp.to"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create SMTP notifier: %w", err)
+		}
+		if err := add("notify.smtp", n); err != nil {
+			return err
+		}
+	}
+	if viper.GetBool("notify.mqtt.enabled") {
+		n, err := newMQTTNotifier(viper.GetString("notify.mqtt.topic"))
+		if err != nil {
+			return fmt.Errorf("failed to create MQTT notifier: %w", err)
+		}
+		if err := add("notify.mqtt", n); err != nil {
+			return err
+		}
+	}
+	if viper.GetBool("notify.command.enabled") {
+		path := viper.GetString("notify.command.path")
+		if path == "" {
+			return fmt.Errorf("notification command must be specified")
+		}
+		if err := add("notify.command", notify.Command{Path: path, Args: viper.GetStringSlice("notify.command.args")}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/outlier"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// newOutlierFilter creates an outlier filter from the outliers configuration:
+//
+//	outliers:
+//	  window: 7
+//	  k: 3
+//	  fields:
+//	    temperature:
+//	      min: -40
+//	      max: 84
+//	      max_rate: 2
+//	      hampel: true
+//	      min_deviation: 0.5
+func newOutlierFilter() (*outlier.Filter, error) {
+	f := &outlier.Filter{
+		Checks: make(map[string]outlier.Check),
+		Window: viper.GetInt("outliers.window"),
+		K:      viper.GetFloat64("outliers.k"),
+	}
+	if f.K == 0 {
+		f.K = 3
+	}
+	for field, v := range viper.GetStringMap("outliers.fields") {
+		if !sensor.IsField(field) {
+			return nil, fmt.Errorf("invalid outlier check: unknown field %s", field)
+		}
+		c, err := parseOutlierCheck(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid outlier check for field %s: %w", field, err)
+		}
+		f.Checks[field] = c
+	}
+	return f, nil
+}
+
+func parseOutlierCheck(cfg interface{}) (c outlier.Check, err error) {
+	settings, ok := toStringMap(cfg)
+	if !ok {
+		err = fmt.Errorf("invalid configuration")
+		return
+	}
+	if v, ok := settings["min"]; ok {
+		var min float64
+		if min, err = toFloat64(v); err != nil {
+			return
+		}
+		c.Min = &min
+	}
+	if v, ok := settings["max"]; ok {
+		var max float64
+		if max, err = toFloat64(v); err != nil {
+			return
+		}
+		c.Max = &max
+	}
+	if v, ok := settings["max_rate"]; ok {
+		if c.MaxRate, err = toFloat64(v); err != nil {
+			return
+		}
+	}
+	if v, ok := settings["min_deviation"]; ok {
+		if c.MinDeviation, err = toFloat64(v); err != nil {
+			return
+		}
+	}
+	c.Hampel, _ = settings["hampel"].(bool)
+	return
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/replay"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+)
+
+// newPipeline creates a measurement pipeline with the configured decoders, processors,
+// detectors and exporters
+func newPipeline(scheduler scanner.Scheduler) *scanner.Pipeline {
+	p := scanner.New(logger, peripherals)
+	p.Scheduler = scheduler
+	p.Exporters = exporters
+	p.Detectors = detectors
+	p.SetDecoders(decoders)
+	p.SetProcessors(processors)
+	p.SetGateway(gateway)
+	if bleScanner != nil {
+		p.SetBLEScanner(bleScanner)
+	}
+	return p
+}
+
+// runPipeline initializes the BLE device and runs the pipeline until it completes, the
+// context is done or the process is interrupted. When replaying, no device is needed and
+// the pipeline also stops at the end of the recording.
+func runPipeline(ctx context.Context, p *scanner.Pipeline) error {
+	if !replaying() {
+		if err := p.Init(device); err != nil {
+			return err
+		}
+	}
+	defer p.Close()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	err := p.Run(ctx)
+	if errors.Is(err, replay.ErrEnd) {
+		logger.Info("All recorded advertisements replayed")
+		return nil
+	}
+	return err
+}
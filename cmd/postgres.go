@@ -15,13 +15,17 @@ func init() {
 	rootCmd.PersistentFlags().Bool("postgres.enabled", false, "Store measurements to PostgreSQL")
 	rootCmd.PersistentFlags().String("postgres.conn", "", "PostgreSQL connection string")
 	rootCmd.PersistentFlags().String("postgres.table", "", "PostgreSQL table")
+	rootCmd.PersistentFlags().String("postgres.events_table", "", "PostgreSQL table for events, leave empty to not store events")
+	rootCmd.PersistentFlags().String("postgres.aggregates_table", "", "PostgreSQL table for aggregates, leave empty to not store aggregates")
 }
 
 func addPostgresExporter(exporters *[]exporter.Exporter) error {
 	ctx := context.Background()
 	connStr := viper.GetString("postgres.conn")
 	table := viper.GetString("postgres.table")
-	exp, err := postgres.New(ctx, connStr, table)
+	eventsTable := viper.GetString("postgres.events_table")
+	aggregatesTable := viper.GetString("postgres.aggregates_table")
+	exp, err := postgres.New(ctx, connStr, table, eventsTable, aggregatesTable)
 	if err != nil {
 		return err
 	}
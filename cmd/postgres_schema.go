@@ -36,6 +36,22 @@ var postgresSchemaCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		eventsTable := viper.GetString("postgres.events_table")
+		if eventsTable != "" {
+			logger.Info("Creating events schema", zap.String("table", eventsTable))
+			_, err = db.ExecContext(cmd.Context(), fmt.Sprintf(pexp.EventsSchemaTmpl, eventsTable))
+			if err != nil {
+				return err
+			}
+		}
+		aggregatesTable := viper.GetString("postgres.aggregates_table")
+		if aggregatesTable != "" {
+			logger.Info("Creating aggregates schema", zap.String("table", aggregatesTable))
+			_, err = db.ExecContext(cmd.Context(), fmt.Sprintf(pexp.AggregatesSchemaTmpl, aggregatesTable))
+			if err != nil {
+				return err
+			}
+		}
 		return nil
 	},
 }
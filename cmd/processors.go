@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/barometer"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/battery"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/calibration"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/condensation"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/linkquality"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/mold"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/orientation"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/psychrometrics"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/weather"
+)
+
+// newProcessors creates the processors applied to measurements in the order they must run
+func newProcessors(calibrations map[string]calibration.Calibration, altitudes map[string]float64, surfaces map[string]condensation.Surface, outdoor map[string]bool) ([]scanner.Processor, error) {
+	var processors []scanner.Processor
+	if viper.GetBool("linkquality.enabled") {
+		// Sequence numbers are tracked before outliers are rejected so that rejections are not lost measurements
+		linkQuality = linkquality.NewTracker(viper.GetDuration("linkquality.window"), viper.GetDuration("linkquality.interval"))
+		expvar.Publish("link_quality", expvar.Func(func() interface{} {
+			return linkQuality.Stats()
+		}))
+		processors = append(processors, linkQuality)
+	}
+	if len(calibrations) > 0 {
+		processors = append(processors, &calibration.Calibrator{Calibrations: calibrations})
+	}
+	if viper.IsSet("outliers") {
+		filter, err := newOutlierFilter()
+		if err != nil {
+			return nil, err
+		}
+		expvar.Publish("outlier_rejections", expvar.Func(func() interface{} {
+			return filter.Rejections()
+		}))
+		processors = append(processors, filter)
+	}
+	if viper.IsSet("altitude") || len(altitudes) > 0 {
+		reducer := &barometer.Reducer{Altitudes: altitudes}
+		if viper.IsSet("altitude") {
+			altitude := viper.GetFloat64("altitude")
+			reducer.Altitude = &altitude
+		}
+		processors = append(processors, reducer)
+	}
+	if len(outdoor) > 0 {
+		processors = append(processors, &weather.Station{Addrs: outdoor})
+	}
+	processors = append(processors, &battery.Estimator{Model: battery.CR2477})
+	processors = append(processors, &orientation.Tracker{Threshold: viper.GetFloat64("orientation.threshold")})
+	if len(surfaces) > 0 {
+		processors = append(processors, &condensation.Detector{
+			Surfaces: surfaces,
+			Margin:   viper.GetFloat64("condensation.margin"),
+			MaxAge:   10 * time.Minute,
+		})
+	}
+	if viper.GetBool("mold.enabled") {
+		model, err := parseMoldModel(viper.GetString("mold.species"), viper.GetString("mold.surface"))
+		if err != nil {
+			return nil, err
+		}
+		acc, err := mold.NewAccumulator(model, viper.GetString("mold.state_file"), viper.GetDuration("mold.save_interval"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mold state: %w", err)
+		}
+		acc.Logger = logger
+		processors = append(processors, acc)
+	}
+	if metrics := viper.GetStringSlice("psychrometrics"); len(metrics) > 0 {
+		calc, err := psychrometrics.NewCalculator(metrics)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, calc)
+	}
+	return processors, nil
+}
+
+func parseMoldModel(species, surface string) (m mold.Model, err error) {
+	switch strings.ToLower(species) {
+	case "pine":
+		m.Species = mold.Pine
+	case "spruce":
+		m.Species = mold.Spruce
+	default:
+		err = fmt.Errorf("unsupported wood species: %s", species)
+		return
+	}
+	switch strings.ToLower(surface) {
+	case "sawn":
+		m.Surface = mold.Sawn
+	case "kiln_dried":
+		m.Surface = mold.KilnDried
+	default:
+		err = fmt.Errorf("unsupported wood surface: %s", surface)
+	}
+	return
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"expvar"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/batch"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/queue"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/retry"
+)
+
+var (
+	queues  []*queue.Exporter
+	retries []*retry.Exporter
+)
+
+// wrapExporter applies the retry buffer, deadband, export queue and batching configured for
+// the most recently added exporter. The deadband is applied outside the retry buffer so that
+// replayed measurements are not filtered out again. Batches are collected outside the queue
+// so that the queue exports and counts whole batches.
+func wrapExporter(key string, exporters []exporter.Exporter) error {
+	if err := applyRetry(key, exporters); err != nil {
+		return err
+	}
+	if err := applyDeadband(key, exporters); err != nil {
+		return err
+	}
+	if err := applyQueue(key, exporters); err != nil {
+		return err
+	}
+	applyBatch(key, exporters)
+	return nil
+}
+
+// applyBatch collects the measurements of the most recently added exporter into batches if
+// the batch size is greater than one
+func applyBatch(key string, exporters []exporter.Exporter) {
+	size := viper.GetInt(exportSetting(key, "batch_size"))
+	if size <= 1 || len(exporters) == 0 {
+		return
+	}
+	i := len(exporters) - 1
+	exporters[i] = batch.New(exporters[i], size, viper.GetDuration(exportSetting(key, "linger")), viper.GetDuration(exportSetting(key, "timeout")), logger)
+}
+
+// applyRetry stores the measurements that the most recently added exporter fails to export
+// in a retry buffer under the retry directory, if one is set
+func applyRetry(key string, exporters []exporter.Exporter) error {
+	dir := viper.GetString("retry.dir")
+	if dir == "" || len(exporters) == 0 {
+		return nil
+	}
+	i := len(exporters) - 1
+	r, err := retry.New(exporters[i], retry.Config{
+		Dir:        filepath.Join(dir, key),
+		MaxSize:    viper.GetInt64("retry.max_size"),
+		MaxAge:     viper.GetDuration("retry.max_age"),
+		MinBackoff: viper.GetDuration("retry.min_backoff"),
+		MaxBackoff: viper.GetDuration("retry.max_backoff"),
+		Timeout:    viper.GetDuration(exportSetting(key, "timeout")),
+	}, logger)
+	if err != nil {
+		return fmt.Errorf("failed to open retry buffer for %s: %w", key, err)
+	}
+	retries = append(retries, r)
+	exporters[i] = r
+	return nil
+}
+
+// applyQueue moves the exports of the most recently added exporter to a queue of its own. The
+// global export settings can be overridden under the key of the exporter:
+//
+//	postgres:
+//	  export:
+//	    queue_size: 1000
+//	    timeout: 30s
+//	    overflow: block
+func applyQueue(key string, exporters []exporter.Exporter) error {
+	if len(exporters) == 0 {
+		return nil
+	}
+	size := viper.GetInt(exportSetting(key, "queue_size"))
+	if size <= 0 {
+		return nil
+	}
+	policy, err := queue.ParsePolicy(viper.GetString(exportSetting(key, "overflow")))
+	if err != nil {
+		return fmt.Errorf("invalid export queue for %s: %w", key, err)
+	}
+	i := len(exporters) - 1
+	q := queue.New(exporters[i], size, viper.GetDuration(exportSetting(key, "timeout")), policy, logger)
+	queues = append(queues, q)
+	exporters[i] = q
+	return nil
+}
+
+// exportSetting returns the key of the export setting of an exporter, falling back to the global setting
+func exportSetting(key, name string) string {
+	if viper.IsSet(key + ".export." + name) {
+		return key + ".export." + name
+	}
+	return "export." + name
+}
+
+// publishQueueStats publishes the counters of the export queues and retry buffers as expvars
+func publishQueueStats() {
+	if len(queues) > 0 {
+		expvar.Publish("export_queues", expvar.Func(func() interface{} {
+			stats := make(map[string]queue.Stats)
+			for _, q := range queues {
+				stats[q.Name()] = q.Stats()
+			}
+			return stats
+		}))
+	}
+	if len(retries) > 0 {
+		expvar.Publish("retry_buffers", expvar.Func(func() interface{} {
+			stats := make(map[string]retry.Stats)
+			for _, r := range retries {
+				stats[r.Name()] = r.Stats()
+			}
+			return stats
+		}))
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/replay"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay FILE",
+	Short: "Replay recorded advertisements through the processors and exporters",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := replay.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		s.Speed = viper.GetFloat64("replay.speed")
+		bleScanner = s
+		logger.Info("Replaying advertisements", zap.String("file", args[0]), zap.Float64("speed", s.Speed))
+		return runPipeline(context.Background(), newPipeline(scanner.Continuous{}))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+}
+
+// newBLEScanner creates the scanner that replays advertisements from replay.file or records
+// them to record.file. It returns a nil scanner if neither is set.
+func newBLEScanner() (scanner.BLEScanner, io.Closer, error) {
+	replayFile := viper.GetString("replay.file")
+	recordFile := viper.GetString("record.file")
+	if replayFile != "" && recordFile != "" {
+		return nil, nil, fmt.Errorf("cannot record advertisements while replaying them")
+	}
+	if replayFile != "" {
+		s, err := replay.Open(replayFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.Speed = viper.GetFloat64("replay.speed")
+		return s, s, nil
+	}
+	if recordFile != "" {
+		f, err := os.OpenFile(recordFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return replay.NewRecorder(scanner.NewBLEScanner(), f, logger), f, nil
+	}
+	return nil, nil, nil
+}
+
+// replaying tells whether advertisements are replayed instead of read from the BLE device
+func replaying() bool {
+	_, ok := bleScanner.(*replay.Scanner)
+	return ok
+}
@@ -3,19 +3,26 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"time"
 
-	"github.com/go-ble/ble"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/niktheblak/ruuvitag-gollector/pkg/calibration"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/condensation"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/console"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/http"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/queue"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/lastseen"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/linkquality"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
 
 var ErrNotEnabled = errors.New("this exporter is not included in the build")
@@ -23,8 +30,16 @@ var ErrNotEnabled = errors.New("this exporter is not included in the build")
 var (
 	logger      *zap.Logger
 	peripherals map[string]string
+	decoders    *sensor.Registry
+	processors  []scanner.Processor
+	detectors   []scanner.Detector
+	lastSeen    *lastseen.Registry
+	linkQuality *linkquality.Tracker
+	gateway     string
 	exporters   []exporter.Exporter
 	device      string
+	bleScanner  scanner.BLEScanner
+	bleCloser   io.Closer
 )
 
 var rootCmd = &cobra.Command{
@@ -33,6 +48,9 @@ var rootCmd = &cobra.Command{
 	SilenceUsage:      true,
 	PersistentPreRunE: run,
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if bleCloser != nil {
+			bleCloser.Close()
+		}
 		if logger != nil {
 			logger.Sync()
 		}
@@ -51,6 +69,37 @@ func init() {
 
 	rootCmd.PersistentFlags().StringToString("ruuvitags", nil, "RuuviTag addresses and names to use")
 	rootCmd.PersistentFlags().String("device", "default", "HCL device to use")
+	rootCmd.PersistentFlags().String("gateway", "", "Gateway ID added to measurements, defaults to hostname")
+	rootCmd.PersistentFlags().StringSlice("decoders", []string{"ruuvi"}, "Sensor data decoders to use (ruuvi, atc, govee, switchbot)")
+	rootCmd.PersistentFlags().Float64("altitude", 0, "Altitude of the RuuviTags in meters for calculating sea level pressure")
+	rootCmd.PersistentFlags().Float64("orientation.threshold", 0, "Pitch or roll change in degrees that flags the orientation of a RuuviTag as changed, 0 to disable")
+	rootCmd.PersistentFlags().StringSlice("psychrometrics", nil, "Derived psychrometric metrics to add to measurements (absolute_humidity, mixing_ratio, vapor_pressure_deficit, wet_bulb, frost_point, heat_index, humidex)")
+	rootCmd.PersistentFlags().Float64("condensation.margin", 2, "Difference in degrees between surface temperature and dew point below which condensation risk is flagged")
+	rootCmd.PersistentFlags().Bool("mold.enabled", false, "Accumulate the VTT mold index of each RuuviTag")
+	rootCmd.PersistentFlags().String("mold.species", "pine", "Wood species of the mold model (pine, spruce)")
+	rootCmd.PersistentFlags().String("mold.surface", "sawn", "Wood surface quality of the mold model (sawn, kiln_dried)")
+	rootCmd.PersistentFlags().String("mold.state_file", "", "File for persisting accumulated mold indexes across restarts")
+	rootCmd.PersistentFlags().Duration("mold.save_interval", 10*time.Minute, "Interval for saving mold indexes to the state file")
+	rootCmd.PersistentFlags().Bool("motion.enabled", false, "Emit motion events when the movement counter of a RuuviTag increases")
+	rootCmd.PersistentFlags().Bool("linkquality.enabled", false, "Track measurement sequence numbers and export link quality statistics")
+	rootCmd.PersistentFlags().Duration("linkquality.window", 10*time.Minute, "Rolling window of link quality statistics")
+	rootCmd.PersistentFlags().Duration("linkquality.interval", 5*time.Minute, "Interval for exporting link quality statistics")
+	rootCmd.PersistentFlags().Duration("offline.timeout", 0, "Time without measurements after which a RuuviTag is flagged offline, 0 to disable")
+	rootCmd.PersistentFlags().String("metrics.addr", "", "Address for serving metrics over HTTP, e.g. :9100")
+	rootCmd.PersistentFlags().Int("export.queue_size", 100, "Number of pending exports queued per exporter, 0 to export synchronously")
+	rootCmd.PersistentFlags().Duration("export.timeout", 10*time.Second, "Timeout of a single export")
+	rootCmd.PersistentFlags().String("export.overflow", string(queue.DropOldest), "What to do when the queue of an exporter is full (block, drop_oldest, drop_newest)")
+	rootCmd.PersistentFlags().Int("export.batch_size", 0, "Number of measurements exported to an exporter in a single batch, 0 to export them one by one")
+	rootCmd.PersistentFlags().Duration("export.linger", 5*time.Second, "Maximum time a measurement waits for its batch to fill up")
+	rootCmd.PersistentFlags().String("retry.dir", "", "Directory for buffering failed exports on disk for retrying, empty to disable")
+	rootCmd.PersistentFlags().Int64("retry.max_size", 100<<20, "Maximum size in bytes of the retry buffer of each exporter")
+	rootCmd.PersistentFlags().Duration("retry.max_age", 7*24*time.Hour, "Age after which buffered measurements are dropped, 0 to keep them indefinitely")
+	rootCmd.PersistentFlags().Duration("retry.min_backoff", time.Second, "Initial delay between retries")
+	rootCmd.PersistentFlags().Duration("retry.max_backoff", 5*time.Minute, "Maximum delay between retries")
+	rootCmd.PersistentFlags().Float64("battery.low_threshold", 0, "Battery level in percent below which a low battery event is emitted once per RuuviTag, 0 to disable")
+	rootCmd.PersistentFlags().String("replay.file", "", "Replay advertisements recorded to the file instead of scanning with the BLE device")
+	rootCmd.PersistentFlags().Float64("replay.speed", 1, "Speed of replaying advertisements relative to real time, 0 to replay as fast as possible")
+	rootCmd.PersistentFlags().String("record.file", "", "Record received advertisements to the file for replaying")
 	rootCmd.PersistentFlags().BoolP("console", "c", false, "Print measurements to console")
 	rootCmd.PersistentFlags().String("loglevel", "info", "Log level")
 
@@ -72,7 +121,8 @@ func initConfig() {
 	}
 }
 
-func run(cmd *cobra.Command, args []string) error {
+// initLogging creates the logger, which is all the setup that commands not reading sensors need
+func initLogging() error {
 	creds := viper.GetString("gcp.credentials")
 	if creds != "" {
 		if err := os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", creds); err != nil {
@@ -118,39 +168,110 @@ func run(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to create logger: %w", err)
 		}
 	}
-	ruuviTags := viper.GetStringMapString("ruuvitags")
-	logger.Info("RuuviTags", zap.Any("ruuvitags", ruuviTags))
+	return nil
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	if err := initLogging(); err != nil {
+		return err
+	}
+	ruuviTags, err := parseRuuviTags(viper.Get("ruuvitags"))
+	if err != nil {
+		return err
+	}
 	peripherals = make(map[string]string)
-	for addr, name := range ruuviTags {
-		peripherals[ble.NewAddr(addr).String()] = name
+	keys := make(map[string][]byte)
+	calibrations := make(map[string]calibration.Calibration)
+	altitudes := make(map[string]float64)
+	for addr, tag := range ruuviTags {
+		peripherals[addr] = tag.Name
+		if tag.Key != nil {
+			keys[addr] = tag.Key
+		}
+		if tag.Calibration != nil {
+			calibrations[addr] = *tag.Calibration
+		}
+		if tag.Altitude != nil {
+			altitudes[addr] = *tag.Altitude
+		}
+	}
+	surfaces := make(map[string]condensation.Surface)
+	outdoor := make(map[string]bool)
+	for addr, tag := range ruuviTags {
+		if tag.Outdoor {
+			outdoor[addr] = true
+		}
+		if tag.SurfaceTemperature != nil {
+			surfaces[addr] = condensation.Surface{Temperature: tag.SurfaceTemperature}
+		} else if tag.SurfaceTag != "" {
+			surfaceAddr, err := resolveTag(tag.SurfaceTag, peripherals)
+			if err != nil {
+				return fmt.Errorf("invalid surface tag for RuuviTag %s: %w", addr, err)
+			}
+			surfaces[addr] = condensation.Surface{Addr: surfaceAddr}
+		}
+	}
+	logger.Info("RuuviTags", zap.Any("ruuvitags", peripherals))
+	decoders, err = newDecoders(viper.GetStringSlice("decoders"), keys)
+	if err != nil {
+		return err
+	}
+	processors, err = newProcessors(calibrations, altitudes, surfaces, outdoor)
+	if err != nil {
+		return err
+	}
+	detectors, err = newDetectors()
+	if err != nil {
+		return err
+	}
+	if addr := viper.GetString("metrics.addr"); addr != "" {
+		startMetricsServer(addr)
 	}
 	if viper.GetBool("console") {
 		exporters = append(exporters, console.Exporter{})
+		if err := applyQueue("console", exporters); err != nil {
+			return err
+		}
 	}
 	if viper.GetBool("influxdb.enabled") {
 		if err := addInfluxDBExporter(&exporters); err != nil {
 			return fmt.Errorf("failed to create InfluxDB exporter: %w", err)
 		}
+		if err := wrapExporter("influxdb", exporters); err != nil {
+			return err
+		}
 	}
 	if viper.GetBool("gcp.pubsub.enabled") {
 		if err := addPubSubExporter(&exporters); err != nil {
 			return fmt.Errorf("failed to create Google Pub/Sub exporter: %w", err)
 		}
+		if err := wrapExporter("gcp.pubsub", exporters); err != nil {
+			return err
+		}
 	}
 	if viper.GetBool("aws.dynamodb.enabled") {
 		if err := addDynamoDBExporter(&exporters); err != nil {
 			return fmt.Errorf("failed to create AWS DynamoDB exporter: %w", err)
 		}
+		if err := wrapExporter("aws.dynamodb", exporters); err != nil {
+			return err
+		}
 	}
 	if viper.GetBool("aws.sqs.enabled") {
 		if err := addSQSExporter(&exporters); err != nil {
 			return fmt.Errorf("failed to create AWS SQS exporter: %w", err)
 		}
+		if err := wrapExporter("aws.sqs", exporters); err != nil {
+			return err
+		}
 	}
 	if viper.GetBool("postgres.enabled") {
 		if err := addPostgresExporter(&exporters); err != nil {
 			return fmt.Errorf("failed to create PostgreSQL exporter: %w", err)
 		}
+		if err := wrapExporter("postgres", exporters); err != nil {
+			return err
+		}
 	}
 	if viper.GetBool("http.enabled") {
 		addr := viper.GetString("http.addr")
@@ -160,12 +281,30 @@ func run(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to create HTTP exporter: %w", err)
 		}
 		exporters = append(exporters, exp)
+		if err := wrapExporter("http", exporters); err != nil {
+			return err
+		}
 	}
 	if viper.GetBool("mqtt.enabled") {
 		if err := addMQTTExporter(&exporters); err != nil {
 			return fmt.Errorf("failed to create MQTT exporter: %w", err)
 		}
+		if err := wrapExporter("mqtt", exporters); err != nil {
+			return err
+		}
+	}
+	if err := addNotifiers(&exporters); err != nil {
+		return err
+	}
+	publishQueueStats()
+	bleScanner, bleCloser, err = newBLEScanner()
+	if err != nil {
+		return err
 	}
 	device = viper.GetString("device")
+	gateway = viper.GetString("gateway")
+	if gateway == "" {
+		gateway, _ = os.Hostname()
+	}
 	return nil
 }
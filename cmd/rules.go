@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ble/ble"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/rules"
+)
+
+// parseRules parses the alerting rules configuration. Tags are given by address or by name:
+//
+//	rules:
+//	  - name: freezer-warm
+//	    tags: [Freezer]
+//	    field: temperature
+//	    operator: ">"
+//	    threshold: -15
+//	    for: 10m
+//	    hysteresis: 1
+//	    cooldown: 1h
+func parseRules(cfg interface{}, peripherals map[string]string) ([]rules.Rule, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	entries, ok := cfg.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid rules configuration")
+	}
+	var result []rules.Rule
+	for i, entry := range entries {
+		settings, ok := toStringMap(entry)
+		if !ok {
+			return nil, fmt.Errorf("invalid configuration for rule %d", i+1)
+		}
+		r, err := parseRule(settings, peripherals)
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration for rule %d: %w", i+1, err)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func parseRule(settings map[string]interface{}, peripherals map[string]string) (r rules.Rule, err error) {
+	if r.Name, err = requiredString(settings, "name"); err != nil {
+		return
+	}
+	if r.Field, err = requiredString(settings, "field"); err != nil {
+		return
+	}
+	op, err := requiredString(settings, "operator")
+	if err != nil {
+		return
+	}
+	r.Operator = rules.Operator(strings.TrimSpace(op))
+	if r.Threshold, err = toFloat64(settings["threshold"]); err != nil {
+		return
+	}
+	if v, ok := settings["hysteresis"]; ok {
+		if r.Hysteresis, err = toFloat64(v); err != nil {
+			return
+		}
+	}
+	if r.For, err = toDuration(settings["for"]); err != nil {
+		return
+	}
+	if r.Cooldown, err = toDuration(settings["cooldown"]); err != nil {
+		return
+	}
+	var tags []interface{}
+	switch v := settings["tags"].(type) {
+	case nil:
+	case string:
+		tags = []interface{}{v}
+	case []interface{}:
+		tags = v
+	default:
+		return r, fmt.Errorf("invalid tags: %v", v)
+	}
+	for _, t := range tags {
+		addr, err := resolveTag(fmt.Sprint(t), peripherals)
+		if err != nil {
+			return r, err
+		}
+		r.Addrs = append(r.Addrs, addr)
+	}
+	return
+}
+
+func requiredString(settings map[string]interface{}, key string) (string, error) {
+	switch v := settings[key].(type) {
+	case nil:
+		return "", fmt.Errorf("missing %s", key)
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid %s: %v", key, v)
+	}
+}
+
+// resolveTag returns the address of the RuuviTag with the given address or name
+func resolveTag(tag string, peripherals map[string]string) (string, error) {
+	for addr, name := range peripherals {
+		if name == tag {
+			return addr, nil
+		}
+	}
+	if strings.Count(tag, ":") == 5 {
+		return ble.NewAddr(tag).String(), nil
+	}
+	return "", fmt.Errorf("unknown RuuviTag: %s", tag)
+}
+
+func toDuration(v interface{}) (time.Duration, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return time.ParseDuration(v)
+	case int:
+		return time.Duration(v) * time.Second, nil
+	default:
+		return 0, fmt.Errorf("invalid duration: %v", v)
+	}
+}
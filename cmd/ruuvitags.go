@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-ble/ble"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/calibration"
+)
+
+type ruuviTag struct {
+	Name        string
+	Key         []byte
+	Calibration *calibration.Calibration
+	Altitude    *float64
+	// SurfaceTemperature and SurfaceTag give the cold surface used for condensation risk
+	SurfaceTemperature *float64
+	SurfaceTag         string
+	// Outdoor enables the pressure tendency and forecast
+	Outdoor bool
+}
+
+// parseRuuviTags parses the ruuvitags configuration. Each RuuviTag address maps either
+// to a plain name or to a map of settings:
+//
+//	ruuvitags:
+//	  "CC:CA:7E:52:CC:34": Backyard
+//	  "FB:E1:B7:04:95:EE":
+//	    name: Upstairs
+//	    key: 00112233445566778899aabbccddeeff
+//	    altitude: 1250
+//	    outdoor: true
+//	    surface_tag: Window
+//	    calibration:
+//	      temperature:
+//	        offset: -0.5
+//	      humidity:
+//	        offset: 3.2
+//	        scale: 1.02
+func parseRuuviTags(cfg interface{}) (map[string]ruuviTag, error) {
+	tags := make(map[string]ruuviTag)
+	entries, ok := toStringMap(cfg)
+	if !ok {
+		if cfg == nil {
+			return tags, nil
+		}
+		return nil, fmt.Errorf("invalid ruuvitags configuration")
+	}
+	for addr, v := range entries {
+		var tag ruuviTag
+		switch v := v.(type) {
+		case string:
+			tag.Name = v
+		default:
+			settings, ok := toStringMap(v)
+			if !ok {
+				return nil, fmt.Errorf("invalid configuration for RuuviTag %s", addr)
+			}
+			tag.Name, _ = settings["name"].(string)
+			if key, ok := settings["key"]; ok {
+				b, err := parseKey(fmt.Sprint(key))
+				if err != nil {
+					return nil, fmt.Errorf("invalid key for RuuviTag %s: %w", addr, err)
+				}
+				tag.Key = b
+			}
+			if cal, ok := settings["calibration"]; ok {
+				c, err := parseCalibration(cal)
+				if err != nil {
+					return nil, fmt.Errorf("invalid calibration for RuuviTag %s: %w", addr, err)
+				}
+				tag.Calibration = &c
+			}
+			if alt, ok := settings["altitude"]; ok {
+				a, err := toFloat64(alt)
+				if err != nil {
+					return nil, fmt.Errorf("invalid altitude for RuuviTag %s: %w", addr, err)
+				}
+				tag.Altitude = &a
+			}
+			if st, ok := settings["surface_temperature"]; ok {
+				t, err := toFloat64(st)
+				if err != nil {
+					return nil, fmt.Errorf("invalid surface temperature for RuuviTag %s: %w", addr, err)
+				}
+				tag.SurfaceTemperature = &t
+			}
+			if st, ok := settings["surface_tag"]; ok {
+				tag.SurfaceTag = fmt.Sprint(st)
+			}
+			tag.Outdoor, _ = settings["outdoor"].(bool)
+		}
+		tags[ble.NewAddr(addr).String()] = tag
+	}
+	return tags, nil
+}
+
+func parseKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.ReplaceAll(s, ":", ""))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 16 {
+		return nil, fmt.Errorf("key must be 16 bytes, was %d bytes", len(key))
+	}
+	return key, nil
+}
+
+func parseCalibration(cfg interface{}) (c calibration.Calibration, err error) {
+	settings, ok := toStringMap(cfg)
+	if !ok {
+		err = fmt.Errorf("invalid calibration configuration")
+		return
+	}
+	if c.Temperature, err = parseCorrection(settings["temperature"]); err != nil {
+		err = fmt.Errorf("temperature: %w", err)
+		return
+	}
+	if c.Humidity, err = parseCorrection(settings["humidity"]); err != nil {
+		err = fmt.Errorf("humidity: %w", err)
+		return
+	}
+	if c.Pressure, err = parseCorrection(settings["pressure"]); err != nil {
+		err = fmt.Errorf("pressure: %w", err)
+	}
+	return
+}
+
+func parseCorrection(cfg interface{}) (c calibration.Correction, err error) {
+	c.Scale = 1
+	if cfg == nil {
+		return
+	}
+	settings, ok := toStringMap(cfg)
+	if !ok {
+		err = fmt.Errorf("invalid correction configuration")
+		return
+	}
+	if v, ok := settings["offset"]; ok {
+		if c.Offset, err = toFloat64(v); err != nil {
+			return
+		}
+	}
+	if v, ok := settings["scale"]; ok {
+		c.Scale, err = toFloat64(v)
+	}
+	return
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("invalid number: %v", v)
+	}
+}
+
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = val
+		}
+		return m, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = val
+		}
+		return m, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/lastseen"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show when each RuuviTag was last seen by a running daemon",
+	// Only the daemon is queried, so exporters, retry buffers and the metrics server are not set up
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return initLogging()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr := viper.GetString("status.addr")
+		if addr == "" {
+			addr = viper.GetString("metrics.addr")
+		}
+		if addr == "" {
+			return fmt.Errorf("daemon metrics address must be specified")
+		}
+		statuses, err := fetchStatus(addr)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses, time.Now())
+		return nil
+	},
+}
+
+func init() {
+	statusCmd.Flags().String("status.addr", "", "Metrics address of the running daemon, defaults to metrics.addr")
+
+	viper.BindPFlags(statusCmd.Flags())
+
+	rootCmd.AddCommand(statusCmd)
+}
+
+// fetchStatus reads the last seen registry from the metrics server of a running daemon
+func fetchStatus(addr string) ([]lastseen.Status, error) {
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(strings.TrimSuffix(addr, "/") + "/debug/vars")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("daemon returned status %s", resp.Status)
+	}
+	var vars struct {
+		LastSeen *[]lastseen.Status `json:"last_seen"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		return nil, fmt.Errorf("failed to decode daemon status: %w", err)
+	}
+	if vars.LastSeen == nil {
+		return nil, fmt.Errorf("offline detection is not enabled in the daemon")
+	}
+	return *vars.LastSeen, nil
+}
+
+func printStatus(statuses []lastseen.Status, now time.Time) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MAC\tNAME\tSTATUS\tLAST SEEN\tRSSI")
+	for _, s := range statuses {
+		state := "offline"
+		if s.Online {
+			state = "online"
+		}
+		seen := "never"
+		if !s.LastSeen.IsZero() {
+			seen = fmt.Sprintf("%s ago", now.Sub(s.LastSeen).Truncate(time.Second))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", s.Addr, s.Name, state, seen, s.RSSI)
+	}
+	w.Flush()
+}
@@ -0,0 +1,130 @@
+package aggregate
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Aggregator collects the measurements of each sensor over a window and computes
+// statistics of each numeric field when the window is flushed
+type Aggregator struct {
+	mu    sync.Mutex
+	start time.Time
+	tags  map[string]*tag
+}
+
+type tag struct {
+	name    string
+	gateway string
+	count   int
+	values  map[string][]float64
+}
+
+// New creates an aggregator with a window starting at the given time
+func New(start time.Time) *Aggregator {
+	return &Aggregator{
+		start: start,
+		tags:  make(map[string]*tag),
+	}
+}
+
+// Add adds the measurement to the current window
+func (a *Aggregator) Add(sd sensor.Data) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	t, ok := a.tags[sd.Addr]
+	if !ok {
+		t = &tag{values: make(map[string][]float64)}
+		a.tags[sd.Addr] = t
+	}
+	t.name = sd.Name
+	t.gateway = sd.Gateway
+	t.count++
+	for field, v := range sd.Values() {
+		t.values[field] = append(t.values[field], v)
+	}
+}
+
+// Flush returns the aggregates of the current window ending at the given time, ordered
+// by sensor address, and starts a new window
+func (a *Aggregator) Flush(end time.Time) []sensor.Aggregate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var addrs []string
+	for addr := range a.tags {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	var aggregates []sensor.Aggregate
+	for _, addr := range addrs {
+		t := a.tags[addr]
+		agg := sensor.Aggregate{
+			Addr:    addr,
+			Name:    t.name,
+			Gateway: t.gateway,
+			Start:   a.start,
+			End:     end,
+			Count:   t.count,
+			Fields:  make(map[string]sensor.Stats),
+		}
+		for field, values := range t.values {
+			agg.Fields[field] = Compute(values)
+		}
+		aggregates = append(aggregates, agg)
+	}
+	a.start = end
+	a.tags = make(map[string]*tag)
+	return aggregates
+}
+
+// Compute computes the statistics of the values in the order they were measured.
+// The standard deviation is the sample standard deviation.
+func Compute(values []float64) (s sensor.Stats) {
+	s.Count = len(values)
+	if s.Count == 0 {
+		return
+	}
+	s.Last = values[len(values)-1]
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	s.Min = sorted[0]
+	s.Max = sorted[len(sorted)-1]
+	s.Median = median(sorted)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	s.Mean = sum / float64(s.Count)
+	if s.Count > 1 {
+		var sq float64
+		for _, v := range values {
+			sq += (v - s.Mean) * (v - s.Mean)
+		}
+		s.StdDev = math.Sqrt(sq / float64(s.Count-1))
+	}
+	return
+}
+
+// Median returns the median of the values
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return median(sorted)
+}
+
+func median(sorted []float64) float64 {
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,60 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestCompute(t *testing.T) {
+	s := Compute([]float64{4, 2, 5, 1})
+	assert.Equal(t, 4, s.Count)
+	assert.Equal(t, 1.0, s.Min)
+	assert.Equal(t, 5.0, s.Max)
+	assert.Equal(t, 3.0, s.Mean)
+	assert.Equal(t, 3.0, s.Median)
+	assert.InDelta(t, 1.826, s.StdDev, 0.001)
+	assert.Equal(t, 1.0, s.Last)
+
+	s = Compute([]float64{21.5})
+	assert.Equal(t, sensor.Stats{Count: 1, Min: 21.5, Max: 21.5, Mean: 21.5, Median: 21.5, Last: 21.5}, s)
+	assert.Equal(t, sensor.Stats{}, Compute(nil))
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 2.0, Median([]float64{3, 1, 2}))
+	assert.Equal(t, 2.5, Median([]float64{4, 1, 3, 2}))
+	assert.Equal(t, 0.0, Median(nil))
+}
+
+func TestAggregator(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	a := New(start)
+	a.Add(sensor.Data{Addr: "cc:ca:7e:52:cc:34", Name: "Backyard", Temperature: sensor.Float64(20), Humidity: sensor.Float64(50)})
+	a.Add(sensor.Data{Addr: "cc:ca:7e:52:cc:34", Name: "Backyard", Temperature: sensor.Float64(22)})
+	a.Add(sensor.Data{Addr: "fb:e1:b7:04:95:ee", Name: "Upstairs", Temperature: sensor.Float64(24)})
+	end := start.Add(5 * time.Minute)
+	aggs := a.Flush(end)
+	require.Len(t, aggs, 2)
+	backyard := aggs[0]
+	assert.Equal(t, "cc:ca:7e:52:cc:34", backyard.Addr)
+	assert.Equal(t, "Backyard", backyard.Name)
+	assert.Equal(t, start, backyard.Start)
+	assert.Equal(t, end, backyard.End)
+	assert.Equal(t, 2, backyard.Count)
+	assert.Equal(t, 21.0, backyard.Fields["temperature"].Mean)
+	assert.Equal(t, 22.0, backyard.Fields["temperature"].Last)
+	assert.Equal(t, 1, backyard.Fields["humidity"].Count)
+	assert.Equal(t, "fb:e1:b7:04:95:ee", aggs[1].Addr)
+
+	// The next window starts where the previous one ended
+	assert.Empty(t, a.Flush(end.Add(5*time.Minute)))
+	a.Add(sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(20)})
+	aggs = a.Flush(end.Add(10 * time.Minute))
+	require.Len(t, aggs, 1)
+	assert.Equal(t, end.Add(5*time.Minute), aggs[0].Start)
+}
@@ -0,0 +1,54 @@
+package barometer
+
+import (
+	"math"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/temperature"
+)
+
+// Standard atmosphere constants
+const (
+	// LapseRate is the temperature lapse rate of the standard atmosphere in K/m
+	LapseRate = 0.0065
+	// StandardTemperature is the sea level temperature of the standard atmosphere in °C
+	StandardTemperature = 15.0
+	// Exponent is g*M/(R*L) of the barometric formula
+	Exponent = 5.257
+)
+
+// SeaLevelPressure reduces the station pressure (hPa) measured at the given altitude (m)
+// and temperature (°C) to sea level using the barometric formula
+func SeaLevelPressure(pressure, temp, altitude float64) float64 {
+	tempInK := temperature.Convert(temp, temperature.Celsius, temperature.Kelvin)
+	return pressure * math.Pow(1-LapseRate*altitude/(tempInK+LapseRate*altitude), -Exponent)
+}
+
+// Reducer adds sea level pressure to measurements of sensors with a known altitude
+type Reducer struct {
+	// Altitude is the altitude (m) of sensors without their own altitude
+	Altitude *float64
+	// Altitudes contains the altitudes of sensors by address
+	Altitudes map[string]float64
+}
+
+// Process calculates sea level pressure for the measurement. The standard atmosphere
+// temperature is used if the measurement has no temperature.
+func (r *Reducer) Process(sd sensor.Data) (sensor.Data, error) {
+	if sd.Pressure == nil {
+		return sd, nil
+	}
+	altitude, ok := r.Altitudes[sd.Addr]
+	if !ok {
+		if r.Altitude == nil {
+			return sd, nil
+		}
+		altitude = *r.Altitude
+	}
+	temp := StandardTemperature - LapseRate*altitude
+	if sd.Temperature != nil {
+		temp = *sd.Temperature
+	}
+	sd.SeaLevelPressure = sensor.Float64(SeaLevelPressure(*sd.Pressure, temp, altitude))
+	return sd, nil
+}
@@ -0,0 +1,44 @@
+package barometer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestSeaLevelPressure(t *testing.T) {
+	assert.Equal(t, 1000.0, SeaLevelPressure(1000, 20, 0))
+	assert.InDelta(t, 1013.25, SeaLevelPressure(898.75, 8.5, 1000), 0.5)
+}
+
+func TestReducer(t *testing.T) {
+	r := &Reducer{
+		Altitude: sensor.Float64(100),
+		Altitudes: map[string]float64{
+			"cc:ca:7e:52:cc:34": 1000,
+		},
+	}
+	sd, err := r.Process(sensor.Data{
+		Addr:        "cc:ca:7e:52:cc:34",
+		Temperature: sensor.Float64(8.5),
+		Pressure:    sensor.Float64(898.75),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sd.SeaLevelPressure)
+	assert.InDelta(t, 1013.25, *sd.SeaLevelPressure, 0.5)
+
+	sd, err = r.Process(sensor.Data{
+		Addr:     "fb:e1:b7:04:95:ee",
+		Pressure: sensor.Float64(1000),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sd.SeaLevelPressure)
+	assert.InDelta(t, 1012.0, *sd.SeaLevelPressure, 0.1)
+
+	sd, err = (&Reducer{}).Process(sensor.Data{Pressure: sensor.Float64(1000)})
+	require.NoError(t, err)
+	assert.Nil(t, sd.SeaLevelPressure)
+}
@@ -0,0 +1,134 @@
+package battery
+
+import (
+	"sync"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Point is a point of a discharge curve
+type Point struct {
+	Voltage float64
+	Level   float64
+}
+
+// Model estimates the remaining charge of a battery from its voltage
+type Model struct {
+	// Curve is the discharge curve at the reference temperature ordered by descending voltage
+	Curve []Point
+	// ReferenceTemperature is the temperature (°C) the discharge curve was measured at
+	ReferenceTemperature float64
+	// TemperatureCoefficient is the voltage sag (V) per degree below the reference temperature
+	TemperatureCoefficient float64
+}
+
+// CR2477 is the discharge curve of the CR2477 lithium coin cell used in RuuviTags
+var CR2477 = Model{
+	Curve: []Point{
+		{3.00, 100},
+		{2.95, 90},
+		{2.90, 80},
+		{2.85, 65},
+		{2.80, 50},
+		{2.75, 35},
+		{2.70, 25},
+		{2.60, 12},
+		{2.50, 6},
+		{2.40, 3},
+		{2.20, 0},
+	},
+	ReferenceTemperature:   20,
+	TemperatureCoefficient: 0.004,
+}
+
+// Compensate returns the voltage the battery would have at the reference temperature
+func (m Model) Compensate(voltage, temp float64) float64 {
+	if temp >= m.ReferenceTemperature {
+		return voltage
+	}
+	return voltage + (m.ReferenceTemperature-temp)*m.TemperatureCoefficient
+}
+
+// Level estimates the remaining charge in percent from the voltage at the reference temperature
+func (m Model) Level(voltage float64) float64 {
+	if len(m.Curve) == 0 {
+		return 0
+	}
+	if voltage >= m.Curve[0].Voltage {
+		return m.Curve[0].Level
+	}
+	for i := 1; i < len(m.Curve); i++ {
+		hi, lo := m.Curve[i-1], m.Curve[i]
+		if voltage >= lo.Voltage {
+			return lo.Level + (voltage-lo.Voltage)/(hi.Voltage-lo.Voltage)*(hi.Level-lo.Level)
+		}
+	}
+	return m.Curve[len(m.Curve)-1].Level
+}
+
+// Estimator adds the estimated battery level to measurements reporting battery voltage.
+// Measurements that already carry a battery level reported by the sensor are left as is.
+type Estimator struct {
+	Model Model
+}
+
+// Process estimates the battery level, compensating for the temperature of the measurement if available
+func (e *Estimator) Process(sd sensor.Data) (sensor.Data, error) {
+	if sd.BatteryVoltage <= 0 || sd.BatteryLevel != nil {
+		return sd, nil
+	}
+	voltage := sd.BatteryVoltage
+	if sd.Temperature != nil {
+		voltage = e.Model.Compensate(voltage, *sd.Temperature)
+	}
+	sd.BatteryLevel = sensor.Float64(e.Model.Level(voltage))
+	return sd, nil
+}
+
+// Hysteresis is the increase in battery level (percent) above the threshold required to
+// consider the battery replaced
+const Hysteresis = 10
+
+// Monitor emits a low battery event once per sensor when its battery level falls below the threshold.
+// The sensor is monitored again after its battery level has recovered.
+type Monitor struct {
+	Threshold float64
+
+	mu  sync.Mutex
+	low map[string]bool
+}
+
+// Detect returns a low battery event if the battery of the sensor has just fallen below the threshold
+func (m *Monitor) Detect(sd sensor.Data) []sensor.Event {
+	if sd.BatteryLevel == nil {
+		return nil
+	}
+	level := *sd.BatteryLevel
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.low == nil {
+		m.low = make(map[string]bool)
+	}
+	if m.low[sd.Addr] {
+		if level >= m.Threshold+Hysteresis {
+			delete(m.low, sd.Addr)
+		}
+		return nil
+	}
+	if level >= m.Threshold {
+		return nil
+	}
+	m.low[sd.Addr] = true
+	return []sensor.Event{
+		{
+			Type:      sensor.EventLowBattery,
+			Addr:      sd.Addr,
+			Name:      sd.Name,
+			Timestamp: sd.Timestamp,
+			Fields: map[string]interface{}{
+				"battery_voltage": sd.BatteryVoltage,
+				"battery_level":   level,
+			},
+		},
+	}
+}
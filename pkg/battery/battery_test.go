@@ -0,0 +1,67 @@
+package battery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, 100.0, CR2477.Level(3.1))
+	assert.Equal(t, 100.0, CR2477.Level(3.0))
+	assert.InDelta(t, 57.5, CR2477.Level(2.825), 0.001)
+	assert.Equal(t, 0.0, CR2477.Level(2.2))
+	assert.Equal(t, 0.0, CR2477.Level(1.9))
+}
+
+func TestCompensate(t *testing.T) {
+	assert.Equal(t, 2.9, CR2477.Compensate(2.9, 25))
+	assert.InDelta(t, 2.98, CR2477.Compensate(2.9, 0), 0.0001)
+}
+
+func TestEstimator(t *testing.T) {
+	e := &Estimator{Model: CR2477}
+	sd, err := e.Process(sensor.Data{BatteryVoltage: 2.8, Temperature: sensor.Float64(20)})
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Float64(50), sd.BatteryLevel)
+
+	// Cold readings sag
+	sd, err = e.Process(sensor.Data{BatteryVoltage: 2.72, Temperature: sensor.Float64(-5)})
+	require.NoError(t, err)
+	assert.InDelta(t, 56, *sd.BatteryLevel, 0.001)
+
+	sd, err = e.Process(sensor.Data{})
+	require.NoError(t, err)
+	assert.Nil(t, sd.BatteryLevel)
+
+	// Battery levels reported by the sensor are kept
+	sd, err = e.Process(sensor.Data{BatteryVoltage: 2.8, BatteryLevel: sensor.Float64(87)})
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Float64(87), sd.BatteryLevel)
+}
+
+func TestMonitor(t *testing.T) {
+	m := &Monitor{Threshold: 20}
+	assert.Empty(t, m.Detect(data(50)))
+	events := m.Detect(data(15))
+	require.Len(t, events, 1)
+	assert.Equal(t, sensor.EventLowBattery, events[0].Type)
+	assert.Equal(t, 15.0, events[0].Fields["battery_level"])
+	// Only once per tag
+	assert.Empty(t, m.Detect(data(14)))
+	assert.Empty(t, m.Detect(data(25)))
+	assert.Empty(t, m.Detect(data(12)))
+	// Battery replaced
+	assert.Empty(t, m.Detect(data(100)))
+	assert.Len(t, m.Detect(data(10)), 1)
+}
+
+func data(level float64) sensor.Data {
+	return sensor.Data{
+		Addr:         "cc:ca:7e:52:cc:34",
+		BatteryLevel: sensor.Float64(level),
+	}
+}
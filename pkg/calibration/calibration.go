@@ -0,0 +1,56 @@
+package calibration
+
+import (
+	"math"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Correction is a linear correction applied to a measured value as value * Scale + Offset.
+// A zero Scale is treated as 1.
+type Correction struct {
+	Offset float64
+	Scale  float64
+}
+
+// Apply applies the correction to the given value
+func (c Correction) Apply(v float64) float64 {
+	scale := c.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return v*scale + c.Offset
+}
+
+// Calibration contains the corrections of a single sensor
+type Calibration struct {
+	Temperature Correction
+	Humidity    Correction
+	Pressure    Correction
+}
+
+// Calibrator applies per-sensor calibrations to measurements and recomputes values derived from them
+type Calibrator struct {
+	// Calibrations contains the calibrations by sensor address
+	Calibrations map[string]Calibration
+}
+
+// Process applies the calibration of the measuring sensor to the measurement
+func (c *Calibrator) Process(sd sensor.Data) (sensor.Data, error) {
+	cal, ok := c.Calibrations[sd.Addr]
+	if !ok {
+		return sd, nil
+	}
+	if sd.Temperature != nil {
+		sd.Temperature = sensor.Float64(cal.Temperature.Apply(*sd.Temperature))
+	}
+	if sd.Humidity != nil {
+		h := cal.Humidity.Apply(*sd.Humidity)
+		sd.Humidity = sensor.Float64(math.Max(0, math.Min(100, h)))
+	}
+	if sd.Pressure != nil {
+		sd.Pressure = sensor.Float64(cal.Pressure.Apply(*sd.Pressure))
+	}
+	sd.DewPoint = sensor.DewPoint(sd.Temperature, sd.Humidity)
+	return sd, nil
+}
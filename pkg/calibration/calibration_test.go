@@ -0,0 +1,74 @@
+package calibration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestCalibrator(t *testing.T) {
+	c := &Calibrator{
+		Calibrations: map[string]Calibration{
+			"cc:ca:7e:52:cc:34": {
+				Temperature: Correction{Offset: -0.5},
+				Humidity:    Correction{Offset: 4, Scale: 1.1},
+				Pressure:    Correction{Offset: 0.3},
+			},
+		},
+	}
+	temp := 20.0
+	sd, err := c.Process(sensor.Data{
+		Addr:        "cc:ca:7e:52:cc:34",
+		Temperature: &temp,
+		Humidity:    sensor.Float64(50),
+		DewPoint:    sensor.DewPoint(sensor.Float64(20), sensor.Float64(50)),
+		Pressure:    sensor.Float64(1000),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Float64(19.5), sd.Temperature)
+	assert.InDelta(t, 59.0, *sd.Humidity, 0.001)
+	assert.Equal(t, sensor.Float64(1000.3), sd.Pressure)
+	assert.Equal(t, sensor.DewPoint(sd.Temperature, sd.Humidity), sd.DewPoint)
+	// The original value must not be modified
+	assert.Equal(t, 20.0, temp)
+
+	sd, err = c.Process(sensor.Data{
+		Addr:     "cc:ca:7e:52:cc:34",
+		Humidity: sensor.Float64(98),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Float64(100), sd.Humidity)
+	assert.Nil(t, sd.Temperature)
+	assert.Nil(t, sd.DewPoint)
+
+	sd, err = c.Process(sensor.Data{
+		Addr:        "fb:e1:b7:04:95:ee",
+		Temperature: sensor.Float64(20),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Float64(20), sd.Temperature)
+}
+
+func TestEstimator(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	e := NewEstimator("cc:ca:7e:52:cc:34", time.Minute)
+	// Measurements received before the first reference measurement are ignored
+	e.Add(sensor.Data{Addr: "fb:e1:b7:04:95:ee", Temperature: sensor.Float64(10), Timestamp: ts})
+	e.Add(sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(20), Humidity: sensor.Float64(40), Timestamp: ts})
+	e.Add(sensor.Data{Addr: "fb:e1:b7:04:95:ee", Temperature: sensor.Float64(20.5), Humidity: sensor.Float64(44), Timestamp: ts.Add(10 * time.Second)})
+	e.Add(sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21), Humidity: sensor.Float64(40), Timestamp: ts.Add(20 * time.Second)})
+	e.Add(sensor.Data{Addr: "fb:e1:b7:04:95:ee", Temperature: sensor.Float64(21.7), Humidity: sensor.Float64(44), Timestamp: ts.Add(30 * time.Second)})
+	// Too old reference measurement
+	e.Add(sensor.Data{Addr: "fb:e1:b7:04:95:ee", Temperature: sensor.Float64(30), Timestamp: ts.Add(5 * time.Minute)})
+	results := e.Results()
+	require.Len(t, results, 1)
+	r := results["fb:e1:b7:04:95:ee"]
+	assert.Equal(t, 2, r.Samples)
+	assert.InDelta(t, -0.6, r.Temperature.Offset, 0.001)
+	assert.InDelta(t, -4.0, r.Humidity.Offset, 0.001)
+	assert.Equal(t, 0.0, r.Pressure.Offset)
+}
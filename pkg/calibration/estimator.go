@@ -0,0 +1,108 @@
+package calibration
+
+import (
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Result contains the estimated calibration of a sensor and the number of samples it is based on
+type Result struct {
+	Calibration
+	Samples int
+}
+
+// Estimator estimates calibration offsets of sensors from a side-by-side run against a reference sensor.
+// Each measurement is paired with the latest reference measurement received before it, if their
+// timestamps are at most MaxAge apart.
+type Estimator struct {
+	Reference string
+	MaxAge    time.Duration
+
+	mu        sync.Mutex
+	reference *sensor.Data
+	sums      map[string]*sums
+}
+
+type sums struct {
+	temperature, humidity, pressure sum
+}
+
+type sum struct {
+	total float64
+	n     int
+}
+
+func (s *sum) add(ref, v *float64) {
+	if ref != nil && v != nil {
+		s.total += *ref - *v
+		s.n++
+	}
+}
+
+func (s sum) mean() float64 {
+	if s.n == 0 {
+		return 0
+	}
+	return s.total / float64(s.n)
+}
+
+// NewEstimator creates an estimator using the sensor with the given address as reference
+func NewEstimator(reference string, maxAge time.Duration) *Estimator {
+	return &Estimator{
+		Reference: reference,
+		MaxAge:    maxAge,
+		sums:      make(map[string]*sums),
+	}
+}
+
+// Add adds a measurement from either the reference or a sensor being calibrated
+func (e *Estimator) Add(sd sensor.Data) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if sd.Addr == e.Reference {
+		e.reference = &sd
+		return
+	}
+	if e.reference == nil {
+		return
+	}
+	age := sd.Timestamp.Sub(e.reference.Timestamp)
+	if age < -e.MaxAge || age > e.MaxAge {
+		return
+	}
+	s, ok := e.sums[sd.Addr]
+	if !ok {
+		s = new(sums)
+		e.sums[sd.Addr] = s
+	}
+	s.temperature.add(e.reference.Temperature, sd.Temperature)
+	s.humidity.add(e.reference.Humidity, sd.Humidity)
+	s.pressure.add(e.reference.Pressure, sd.Pressure)
+}
+
+// Results returns the estimated offsets by sensor address
+func (e *Estimator) Results() map[string]Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	results := make(map[string]Result)
+	for addr, s := range e.sums {
+		samples := s.temperature.n
+		if s.humidity.n > samples {
+			samples = s.humidity.n
+		}
+		if s.pressure.n > samples {
+			samples = s.pressure.n
+		}
+		results[addr] = Result{
+			Calibration: Calibration{
+				Temperature: Correction{Offset: s.temperature.mean(), Scale: 1},
+				Humidity:    Correction{Offset: s.humidity.mean(), Scale: 1},
+				Pressure:    Correction{Offset: s.pressure.mean(), Scale: 1},
+			},
+			Samples: samples,
+		}
+	}
+	return results
+}
@@ -0,0 +1,66 @@
+package condensation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Surface is the cold surface compared to the dew point of a sensor. Either the temperature
+// of the surface or the address of a sensor measuring the surface is given.
+type Surface struct {
+	Temperature *float64
+	Addr        string
+}
+
+// Detector flags measurements where the dew point of the air is within Margin degrees
+// of the temperature of a cold surface, meaning water may condense on the surface
+type Detector struct {
+	// Surfaces contains the cold surfaces by address of the sensor measuring the air
+	Surfaces map[string]Surface
+	Margin   float64
+	// MaxAge is the maximum age of surface sensor measurements
+	MaxAge time.Duration
+
+	mu     sync.Mutex
+	latest map[string]sensor.Data
+}
+
+// Process adds the condensation margin and risk to measurements of sensors with a configured surface
+func (d *Detector) Process(sd sensor.Data) (sensor.Data, error) {
+	surfaceTemp, ok := d.surfaceTemperature(sd)
+	if !ok || sd.DewPoint == nil {
+		return sd, nil
+	}
+	margin := surfaceTemp - *sd.DewPoint
+	sd.CondensationMargin = sensor.Float64(margin)
+	sd.CondensationRisk = sensor.Bool(margin <= d.Margin)
+	return sd, nil
+}
+
+func (d *Detector) surfaceTemperature(sd sensor.Data) (float64, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.latest == nil {
+		d.latest = make(map[string]sensor.Data)
+	}
+	if sd.Temperature != nil {
+		d.latest[sd.Addr] = sd
+	}
+	s, ok := d.Surfaces[sd.Addr]
+	if !ok {
+		return 0, false
+	}
+	if s.Temperature != nil {
+		return *s.Temperature, true
+	}
+	surface, ok := d.latest[s.Addr]
+	if !ok {
+		return 0, false
+	}
+	if d.MaxAge > 0 && sd.Timestamp.Sub(surface.Timestamp) > d.MaxAge {
+		return 0, false
+	}
+	return *surface.Temperature, true
+}
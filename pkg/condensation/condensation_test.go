@@ -0,0 +1,66 @@
+package condensation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+const (
+	crawlspace = "cc:ca:7e:52:cc:34"
+	floor      = "fb:e1:b7:04:95:ee"
+	attic      = "e8:e0:c6:0b:b8:c5"
+)
+
+func TestDetector(t *testing.T) {
+	d := &Detector{
+		Surfaces: map[string]Surface{
+			crawlspace: {Addr: floor},
+			attic:      {Temperature: sensor.Float64(-2)},
+		},
+		Margin: 1,
+		MaxAge: 10 * time.Minute,
+	}
+	tests := []struct {
+		name   string
+		data   sensor.Data
+		margin *float64
+		risk   *bool
+	}{
+		{
+			name: "no surface measurement yet",
+			data: sensor.Data{Addr: crawlspace, Temperature: sensor.Float64(12), DewPoint: sensor.Float64(9), Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "surface measurement",
+			data: sensor.Data{Addr: floor, Temperature: sensor.Float64(9.5), DewPoint: sensor.Float64(5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:   "surface from another tag",
+			data:   sensor.Data{Addr: crawlspace, Temperature: sensor.Float64(12), DewPoint: sensor.Float64(9), Timestamp: time.Date(2020, time.January, 1, 12, 1, 0, 0, time.UTC)},
+			margin: sensor.Float64(0.5),
+			risk:   sensor.Bool(true),
+		},
+		{
+			name: "surface measurement too old",
+			data: sensor.Data{Addr: crawlspace, Temperature: sensor.Float64(12), DewPoint: sensor.Float64(9), Timestamp: time.Date(2020, time.January, 1, 13, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:   "fixed surface temperature",
+			data:   sensor.Data{Addr: attic, Temperature: sensor.Float64(5), DewPoint: sensor.Float64(-6), Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)},
+			margin: sensor.Float64(4),
+			risk:   sensor.Bool(false),
+		},
+	}
+	// The cases run in order since the detector keeps the latest surface measurements
+	for _, tt := range tests {
+		sd, err := d.Process(tt.data)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.margin, sd.CondensationMargin, tt.name)
+		assert.Equal(t, tt.risk, sd.CondensationRisk, tt.name)
+	}
+}
@@ -47,6 +47,21 @@ func Calculate(temp float64, unit temperature.Unit, humidity float64) (float64,
 	return temperature.Convert(dpInK, temperature.Kelvin, unit), err
 }
 
+// SaturationVaporPressure calculates saturation vapor pressure (Pa) over water, or over ice below freezing
+func SaturationVaporPressure(tempInK float64) float64 {
+	return pvs(tempInK)
+}
+
+// SaturationVaporPressureWater calculates saturation vapor pressure (Pa) over water
+func SaturationVaporPressureWater(tempInK float64) float64 {
+	return pvsWater(tempInK)
+}
+
+// SaturationVaporPressureIce calculates saturation vapor pressure (Pa) over ice
+func SaturationVaporPressureIce(tempInK float64) float64 {
+	return pvsIce(tempInK)
+}
+
 func pvs(tempInK float64) float64 {
 	if tempInK < temperature.CelsiusOffset {
 		return pvsIce(tempInK)
@@ -2,6 +2,7 @@ package dynamodb
 
 type Config struct {
 	Table           string
+	AggregatesTable string
 	Region          string
 	AccessKeyID     string
 	SecretAccessKey string
@@ -4,6 +4,8 @@ package dynamodb
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -15,10 +17,18 @@ import (
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
 
+const (
+	// maxBatchItems is the maximum number of items in a BatchWriteItem request
+	maxBatchItems = 25
+	// maxBatchAttempts is the number of times unprocessed items of a batch are written
+	maxBatchAttempts = 5
+)
+
 type dynamoDBExporter struct {
-	sess  *session.Session
-	db    dynamodbiface.DynamoDBAPI
-	table string
+	sess            *session.Session
+	db              dynamodbiface.DynamoDBAPI
+	table           string
+	aggregatesTable string
 }
 
 func New(cfg Config) (exporter.Exporter, error) {
@@ -31,9 +41,10 @@ func New(cfg Config) (exporter.Exporter, error) {
 	}
 	db := dynamodb.New(sess)
 	return &dynamoDBExporter{
-		sess:  sess,
-		db:    db,
-		table: cfg.Table,
+		sess:            sess,
+		db:              db,
+		table:           cfg.Table,
+		aggregatesTable: cfg.AggregatesTable,
 	}, nil
 }
 
@@ -42,13 +53,25 @@ func (e *dynamoDBExporter) Name() string {
 }
 
 func (e *dynamoDBExporter) Export(ctx context.Context, data sensor.Data) error {
-	item, err := dynamodbattribute.MarshalMap(data)
+	return e.put(ctx, e.table, data)
+}
+
+// ExportAggregate stores the aggregate to the aggregates table if it is configured
+func (e *dynamoDBExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	if e.aggregatesTable == "" {
+		return nil
+	}
+	return e.put(ctx, e.aggregatesTable, agg)
+}
+
+func (e *dynamoDBExporter) put(ctx context.Context, table string, v interface{}) error {
+	item, err := dynamodbattribute.MarshalMap(v)
 	if err != nil {
 		return err
 	}
 	input := &dynamodb.PutItemInput{
 		Item:      item,
-		TableName: aws.String(e.table),
+		TableName: aws.String(table),
 	}
 	_, err = e.db.PutItemWithContext(ctx, input)
 	if err != nil {
@@ -57,6 +80,75 @@ func (e *dynamoDBExporter) Export(ctx context.Context, data sensor.Data) error {
 	return nil
 }
 
+// ExportBatch writes the measurements in batches of up to 25 items. The measurements left
+// unwritten by an error are returned in an exporter.BatchError.
+func (e *dynamoDBExporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxBatchItems {
+			n = maxBatchItems
+		}
+		requests := make([]*dynamodb.WriteRequest, n)
+		for i, d := range data[:n] {
+			item, err := dynamodbattribute.MarshalMap(d)
+			if err != nil {
+				return &exporter.BatchError{Failed: data, Err: err}
+			}
+			requests[i] = &dynamodb.WriteRequest{
+				PutRequest: &dynamodb.PutRequest{Item: item},
+			}
+		}
+		if unprocessed, err := e.batchWrite(ctx, requests); err != nil {
+			failed, uerr := unmarshalRequests(unprocessed)
+			if uerr != nil {
+				return &exporter.BatchError{Failed: data, Err: err}
+			}
+			return &exporter.BatchError{Failed: append(failed, data[n:]...), Err: err}
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// batchWrite writes the requests to the measurements table, retrying the items that DynamoDB
+// leaves unprocessed when the table is throttled. The requests that were not written are
+// returned with the error.
+func (e *dynamoDBExporter) batchWrite(ctx context.Context, requests []*dynamodb.WriteRequest) ([]*dynamodb.WriteRequest, error) {
+	backoff := 50 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		resp, err := e.db.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{e.table: requests},
+		})
+		if err != nil {
+			return requests, err
+		}
+		requests = resp.UnprocessedItems[e.table]
+		if len(requests) == 0 {
+			return nil, nil
+		}
+		if attempt == maxBatchAttempts {
+			return requests, fmt.Errorf("%d items left unprocessed", len(requests))
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return requests, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// unmarshalRequests returns the measurements of the put requests
+func unmarshalRequests(requests []*dynamodb.WriteRequest) ([]sensor.Data, error) {
+	data := make([]sensor.Data, len(requests))
+	for i, r := range requests {
+		if err := dynamodbattribute.UnmarshalMap(r.PutRequest.Item, &data[i]); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
 func (e *dynamoDBExporter) Close() error {
 	return nil
 }
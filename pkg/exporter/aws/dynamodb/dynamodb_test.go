@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,7 +19,23 @@ import (
 
 type mockDynamoDBClient struct {
 	dynamodbiface.DynamoDBAPI
-	t *testing.T
+	t           *testing.T
+	batches     [][]*dynamodb.WriteRequest
+	unprocessed int
+	throttled   int
+}
+
+func (m *mockDynamoDBClient) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	requests := input.RequestItems["test_table"]
+	m.batches = append(m.batches, requests)
+	out := &dynamodb.BatchWriteItemOutput{}
+	if m.unprocessed > 0 {
+		out.UnprocessedItems = map[string][]*dynamodb.WriteRequest{"test_table": requests[:m.unprocessed]}
+		m.unprocessed = 0
+	} else if m.throttled > 0 && len(requests) >= m.throttled {
+		out.UnprocessedItems = map[string][]*dynamodb.WriteRequest{"test_table": requests[:m.throttled]}
+	}
+	return out, nil
 }
 
 func (m *mockDynamoDBClient) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
@@ -39,16 +56,65 @@ func TestExport(t *testing.T) {
 	data := sensor.Data{
 		Addr:            "CC:CA:7E:52:CC:34",
 		Name:            "Backyard",
-		Temperature:     21.5,
-		Humidity:        60,
-		Pressure:        1002,
+		Temperature:     sensor.Float64(21.5),
+		Humidity:        sensor.Float64(60),
+		Pressure:        sensor.Float64(1002),
 		BatteryVoltage:  50,
-		AccelerationX:   0,
-		AccelerationY:   0,
-		AccelerationZ:   0,
+		AccelerationX:   sensor.Int(0),
+		AccelerationY:   sensor.Int(0),
+		AccelerationZ:   sensor.Int(0),
 		MovementCounter: 1,
 		Timestamp:       time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
 	}
 	err := exp.Export(ctx, data)
 	require.NoError(t, err)
 }
+
+func TestExportBatch(t *testing.T) {
+	client := &mockDynamoDBClient{t: t, unprocessed: 3}
+	exp := &dynamoDBExporter{
+		db:    client,
+		table: "test_table",
+	}
+	data := make([]sensor.Data, 30)
+	for i := range data {
+		data[i] = sensor.Data{
+			Addr:        "CC:CA:7E:52:CC:34",
+			Name:        "Backyard",
+			Temperature: sensor.Float64(21.5),
+			Timestamp:   time.Date(2020, time.January, 1, 0, i, 0, 0, time.UTC),
+		}
+	}
+	err := exp.ExportBatch(context.Background(), data)
+	require.NoError(t, err)
+	require.Len(t, client.batches, 3)
+	assert.Len(t, client.batches[0], 25)
+	assert.Len(t, client.batches[1], 3)
+	assert.Len(t, client.batches[2], 5)
+	assert.Equal(t, "21.5", *client.batches[2][0].PutRequest.Item["temperature"].N)
+}
+
+func TestExportBatchThrottled(t *testing.T) {
+	client := &mockDynamoDBClient{t: t, throttled: 2}
+	exp := &dynamoDBExporter{
+		db:    client,
+		table: "test_table",
+	}
+	data := make([]sensor.Data, 30)
+	for i := range data {
+		data[i] = sensor.Data{
+			Addr:        "CC:CA:7E:52:CC:34",
+			Name:        "Backyard",
+			Temperature: sensor.Float64(21.5),
+			Timestamp:   time.Date(2020, time.January, 1, 0, i, 0, 0, time.UTC),
+		}
+	}
+	err := exp.ExportBatch(context.Background(), data)
+	require.Error(t, err)
+	// The items left unprocessed and the batches not yet written are returned
+	failed := exporter.Failed(data, err)
+	require.Len(t, failed, 7)
+	assert.True(t, data[0].Timestamp.Equal(failed[0].Timestamp))
+	assert.True(t, data[1].Timestamp.Equal(failed[1].Timestamp))
+	assert.Equal(t, data[25:], failed[2:])
+}
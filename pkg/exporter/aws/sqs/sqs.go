@@ -5,6 +5,8 @@ package sqs
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -15,6 +17,9 @@ import (
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
 
+// maxBatchEntries is the maximum number of messages in a SendMessageBatch request
+const maxBatchEntries = 10
+
 type sqsExporter struct {
 	sess     *session.Session
 	sqs      sqsiface.SQSAPI
@@ -54,23 +59,23 @@ func (e *sqsExporter) Name() string {
 }
 
 func (e *sqsExporter) Export(ctx context.Context, data sensor.Data) error {
-	body, err := json.Marshal(data)
+	return e.send(ctx, data.Addr, data.Name, "", data)
+}
+
+// ExportAggregate sends the aggregate as a message with the type attribute set to aggregate
+func (e *sqsExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	return e.send(ctx, agg.Addr, agg.Name, "aggregate", agg)
+}
+
+func (e *sqsExporter) send(ctx context.Context, addr, name, msgType string, v interface{}) error {
+	body, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
 	input := &awssqs.SendMessageInput{
-		MessageAttributes: map[string]*awssqs.MessageAttributeValue{
-			"mac": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(data.Addr),
-			},
-			"name": {
-				DataType:    aws.String("String"),
-				StringValue: aws.String(data.Name),
-			},
-		},
-		MessageBody: aws.String(string(body)),
-		QueueUrl:    aws.String(e.queueUrl),
+		MessageAttributes: attributes(addr, name, msgType),
+		MessageBody:       aws.String(string(body)),
+		QueueUrl:          aws.String(e.queueUrl),
 	}
 	_, err = e.sqs.SendMessageWithContext(ctx, input)
 	if err != nil {
@@ -79,6 +84,73 @@ func (e *sqsExporter) Export(ctx context.Context, data sensor.Data) error {
 	return nil
 }
 
+// ExportBatch sends the measurements in batches of up to ten messages. The measurements of
+// the messages that SQS fails to send are returned in an exporter.BatchError.
+func (e *sqsExporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	var failed []sensor.Data
+	var failErr error
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxBatchEntries {
+			n = maxBatchEntries
+		}
+		entries := make([]*awssqs.SendMessageBatchRequestEntry, n)
+		for i, d := range data[:n] {
+			body, err := json.Marshal(d)
+			if err != nil {
+				return &exporter.BatchError{Failed: append(failed, data...), Err: err}
+			}
+			entries[i] = &awssqs.SendMessageBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(i)),
+				MessageAttributes: attributes(d.Addr, d.Name, ""),
+				MessageBody:       aws.String(string(body)),
+			}
+		}
+		resp, err := e.sqs.SendMessageBatchWithContext(ctx, &awssqs.SendMessageBatchInput{
+			Entries:  entries,
+			QueueUrl: aws.String(e.queueUrl),
+		})
+		if err != nil {
+			return &exporter.BatchError{Failed: append(failed, data...), Err: err}
+		}
+		var chunkFailed []sensor.Data
+		for _, f := range resp.Failed {
+			i, err := strconv.Atoi(aws.StringValue(f.Id))
+			if err != nil || i < 0 || i >= n {
+				return &exporter.BatchError{Failed: append(failed, data...), Err: fmt.Errorf("unknown failed message ID: %s", aws.StringValue(f.Id))}
+			}
+			chunkFailed = append(chunkFailed, data[i])
+			failErr = fmt.Errorf("failed to send message: %s", aws.StringValue(f.Message))
+		}
+		failed = append(failed, chunkFailed...)
+		data = data[n:]
+	}
+	if len(failed) > 0 {
+		return &exporter.BatchError{Failed: failed, Err: failErr}
+	}
+	return nil
+}
+
+func attributes(addr, name, msgType string) map[string]*awssqs.MessageAttributeValue {
+	attrs := map[string]*awssqs.MessageAttributeValue{
+		"mac": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(addr),
+		},
+		"name": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(name),
+		},
+	}
+	if msgType != "" {
+		attrs["type"] = &awssqs.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(msgType),
+		}
+	}
+	return attrs
+}
+
 func (e *sqsExporter) Close() error {
 	return nil
 }
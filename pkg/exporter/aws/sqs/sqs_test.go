@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,17 +19,33 @@ import (
 
 type mockSQSClient struct {
 	sqsiface.SQSAPI
-	t *testing.T
+	t       *testing.T
+	batches []*sqs.SendMessageBatchInput
+	failed  []string
 }
 
 func (m *mockSQSClient) SendMessageWithContext(ctx aws.Context, input *sqs.SendMessageInput, opts ...request.Option) (*sqs.SendMessageOutput, error) {
 	assert := assert.New(m.t)
 	assert.Equal("CC:CA:7E:52:CC:34", *input.MessageAttributes["mac"].StringValue)
 	assert.Equal("Backyard", *input.MessageAttributes["name"].StringValue)
-	assert.Equal("{\"mac\":\"CC:CA:7E:52:CC:34\",\"name\":\"Backyard\",\"temperature\":21.5,\"humidity\":60,\"pressure\":1002,\"battery_voltage\":50,\"acceleration_x\":0,\"acceleration_y\":0,\"acceleration_z\":0,\"movement_counter\":1,\"measurement_number\":0,\"ts\":\"2020-01-01T00:00:00Z\"}", *input.MessageBody)
+	assert.Equal("{\"mac\":\"CC:CA:7E:52:CC:34\",\"name\":\"Backyard\",\"temperature\":21.5,\"humidity\":60,\"pressure\":1002,\"battery_voltage\":50,\"acceleration_x\":0,\"acceleration_y\":0,\"acceleration_z\":0,\"movement_counter\":1,\"ts\":\"2020-01-01T00:00:00Z\"}", *input.MessageBody)
 	return &sqs.SendMessageOutput{}, nil
 }
 
+func (m *mockSQSClient) SendMessageBatchWithContext(ctx aws.Context, input *sqs.SendMessageBatchInput, opts ...request.Option) (*sqs.SendMessageBatchOutput, error) {
+	m.batches = append(m.batches, input)
+	resp := &sqs.SendMessageBatchOutput{}
+	for _, id := range m.failed {
+		resp.Failed = append(resp.Failed, &sqs.BatchResultErrorEntry{
+			Id:      aws.String(id),
+			Code:    aws.String("InternalError"),
+			Message: aws.String("internal error"),
+		})
+	}
+	m.failed = nil
+	return resp, nil
+}
+
 func TestExport(t *testing.T) {
 	exp := &sqsExporter{
 		sqs:      &mockSQSClient{t: t},
@@ -38,16 +55,61 @@ func TestExport(t *testing.T) {
 	data := sensor.Data{
 		Addr:            "CC:CA:7E:52:CC:34",
 		Name:            "Backyard",
-		Temperature:     21.5,
-		Humidity:        60,
-		Pressure:        1002,
+		Temperature:     sensor.Float64(21.5),
+		Humidity:        sensor.Float64(60),
+		Pressure:        sensor.Float64(1002),
 		BatteryVoltage:  50,
-		AccelerationX:   0,
-		AccelerationY:   0,
-		AccelerationZ:   0,
+		AccelerationX:   sensor.Int(0),
+		AccelerationY:   sensor.Int(0),
+		AccelerationZ:   sensor.Int(0),
 		MovementCounter: 1,
 		Timestamp:       time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
 	}
 	err := exp.Export(ctx, data)
 	require.NoError(t, err)
 }
+
+func TestExportBatch(t *testing.T) {
+	client := &mockSQSClient{t: t}
+	exp := &sqsExporter{
+		sqs:      client,
+		queueUrl: "http://localhost/test_queue",
+	}
+	data := make([]sensor.Data, 12)
+	for i := range data {
+		data[i] = sensor.Data{
+			Addr:      "CC:CA:7E:52:CC:34",
+			Name:      "Backyard",
+			Timestamp: time.Date(2020, time.January, 1, 0, i, 0, 0, time.UTC),
+		}
+	}
+	err := exp.ExportBatch(context.Background(), data)
+	require.NoError(t, err)
+	require.Len(t, client.batches, 2)
+	assert.Len(t, client.batches[0].Entries, 10)
+	assert.Len(t, client.batches[1].Entries, 2)
+	assert.Equal(t, "1", *client.batches[1].Entries[1].Id)
+	assert.Equal(t, "Backyard", *client.batches[1].Entries[1].MessageAttributes["name"].StringValue)
+	assert.Equal(t, "http://localhost/test_queue", *client.batches[0].QueueUrl)
+}
+
+func TestExportBatchPartialFailure(t *testing.T) {
+	client := &mockSQSClient{t: t, failed: []string{"1"}}
+	exp := &sqsExporter{
+		sqs:      client,
+		queueUrl: "http://localhost/test_queue",
+	}
+	data := make([]sensor.Data, 12)
+	for i := range data {
+		data[i] = sensor.Data{
+			Addr:      "CC:CA:7E:52:CC:34",
+			Name:      "Backyard",
+			Timestamp: time.Date(2020, time.January, 1, 0, i, 0, 0, time.UTC),
+		}
+	}
+	err := exp.ExportBatch(context.Background(), data)
+	require.Error(t, err)
+	// The following batches are still sent and only the failed message is returned
+	require.Len(t, client.batches, 2)
+	assert.Equal(t, []sensor.Data{data[1]}, exporter.Failed(data, err))
+}
@@ -0,0 +1,144 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Exporter collects measurements into batches that are exported to the wrapped exporter when
+// they reach the batch size or when the linger time has passed since the first measurement
+// of the batch was added. Exporters that do not support batches receive the measurements of
+// a batch one by one. Events and aggregates are forwarded without batching. Batches flushed
+// after the linger time have no caller to return errors to, so the errors are logged. To have
+// them counted, wrap a queue.Exporter, which exports and counts each batch.
+type Exporter struct {
+	exp     exporter.Exporter
+	size    int
+	linger  time.Duration
+	timeout time.Duration
+	logger  *zap.Logger
+
+	// flushMu is held while a batch is exported so that batches are exported in order
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	batch   []sensor.Data
+	timer   *time.Timer
+}
+
+// New wraps the exporter in a batching exporter. Batches flushed after the linger time are
+// given the timeout to be exported.
+func New(exp exporter.Exporter, size int, linger, timeout time.Duration, logger *zap.Logger) *Exporter {
+	if size < 1 {
+		size = 1
+	}
+	return &Exporter{
+		exp:     exp,
+		size:    size,
+		linger:  linger,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+func (e *Exporter) Name() string {
+	return e.exp.Name()
+}
+
+// Export adds the measurement to the current batch. If the batch becomes full, it is exported
+// before returning.
+func (e *Exporter) Export(ctx context.Context, data sensor.Data) error {
+	return e.ExportBatch(ctx, []sensor.Data{data})
+}
+
+// ExportBatch adds the measurements to the current batch and exports it if it is full
+func (e *Exporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	e.mu.Lock()
+	e.batch = append(e.batch, data...)
+	full := len(e.batch) >= e.size
+	if !full && e.timer == nil && e.linger > 0 {
+		e.timer = time.AfterFunc(e.linger, e.flushLinger)
+	}
+	e.mu.Unlock()
+	if full {
+		return e.flush(ctx)
+	}
+	return nil
+}
+
+func (e *Exporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	if ee, ok := e.exp.(exporter.EventExporter); ok {
+		return ee.ExportEvent(ctx, event)
+	}
+	return nil
+}
+
+func (e *Exporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	if ae, ok := e.exp.(exporter.AggregateExporter); ok {
+		return ae.ExportAggregate(ctx, agg)
+	}
+	return nil
+}
+
+// Close exports the pending measurements and closes the wrapped exporter
+func (e *Exporter) Close() error {
+	ctx, cancel := e.context()
+	defer cancel()
+	err := e.flush(ctx)
+	if cerr := e.exp.Close(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+func (e *Exporter) flushLinger() {
+	ctx, cancel := e.context()
+	defer cancel()
+	if err := e.flush(ctx); err != nil {
+		e.logger.Error("Failed to export batch", zap.String("exporter", e.exp.Name()), zap.Error(err))
+	}
+}
+
+// flush exports the pending measurements in batches of at most the batch size. The
+// measurements that were not exported are returned in an exporter.BatchError.
+func (e *Exporter) flush(ctx context.Context) error {
+	e.flushMu.Lock()
+	defer e.flushMu.Unlock()
+	e.mu.Lock()
+	pending := e.batch
+	e.batch = nil
+	if e.timer != nil {
+		e.timer.Stop()
+		e.timer = nil
+	}
+	e.mu.Unlock()
+	var failed []sensor.Data
+	var err error
+	for len(pending) > 0 {
+		n := len(pending)
+		if n > e.size {
+			n = e.size
+		}
+		if berr := exporter.ExportBatch(ctx, e.exp, pending[:n]); berr != nil {
+			failed = append(failed, exporter.Failed(pending[:n], berr)...)
+			err = berr
+		}
+		pending = pending[n:]
+	}
+	if err != nil {
+		return &exporter.BatchError{Failed: failed, Err: err}
+	}
+	return nil
+}
+
+func (e *Exporter) context() (context.Context, context.CancelFunc) {
+	if e.timeout > 0 {
+		return context.WithTimeout(context.Background(), e.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
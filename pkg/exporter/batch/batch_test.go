@@ -0,0 +1,103 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockExporter struct {
+	mu      sync.Mutex
+	reject  string
+	batches [][]string
+}
+
+func (m *mockExporter) Name() string {
+	return "Mock"
+}
+
+func (m *mockExporter) Export(ctx context.Context, data sensor.Data) error {
+	return m.ExportBatch(ctx, []sensor.Data{data})
+}
+
+func (m *mockExporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	var failed []sensor.Data
+	for _, d := range data {
+		if d.Name == m.reject {
+			failed = append(failed, d)
+			continue
+		}
+		names = append(names, d.Name)
+	}
+	m.batches = append(m.batches, names)
+	if len(failed) > 0 {
+		return &exporter.BatchError{Failed: failed, Err: errors.New("rejected")}
+	}
+	return nil
+}
+
+func (m *mockExporter) Close() error {
+	return nil
+}
+
+func (m *mockExporter) exported() [][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.batches
+}
+
+func export(t *testing.T, exp *Exporter, names ...string) {
+	for _, name := range names {
+		require.NoError(t, exp.Export(context.Background(), sensor.Data{Name: name}))
+	}
+}
+
+func TestFlushOnSize(t *testing.T) {
+	mock := new(mockExporter)
+	exp := New(mock, 2, time.Hour, time.Second, zap.NewNop())
+	export(t, exp, "a", "b", "c")
+	assert.Equal(t, [][]string{{"a", "b"}}, mock.exported())
+	require.NoError(t, exp.ExportBatch(context.Background(), []sensor.Data{{Name: "d"}, {Name: "e"}, {Name: "f"}, {Name: "g"}}))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e", "f"}, {"g"}}, mock.exported())
+}
+
+func TestFlushOnLinger(t *testing.T) {
+	mock := new(mockExporter)
+	exp := New(mock, 10, 10*time.Millisecond, time.Second, zap.NewNop())
+	export(t, exp, "a", "b")
+	assert.Empty(t, mock.exported())
+	require.Eventually(t, func() bool {
+		return len(mock.exported()) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, [][]string{{"a", "b"}}, mock.exported())
+}
+
+func TestFlushOnClose(t *testing.T) {
+	mock := new(mockExporter)
+	exp := New(mock, 10, time.Hour, time.Second, zap.NewNop())
+	export(t, exp, "a", "b")
+	require.NoError(t, exp.Close())
+	assert.Equal(t, [][]string{{"a", "b"}}, mock.exported())
+}
+
+func TestFlushPartialFailure(t *testing.T) {
+	mock := &mockExporter{reject: "b"}
+	exp := New(mock, 2, time.Hour, time.Second, zap.NewNop())
+	err := exp.ExportBatch(context.Background(), []sensor.Data{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}})
+	require.Error(t, err)
+	// The following batches are still exported and only the failed measurements are returned
+	assert.Equal(t, [][]string{{"a"}, {"c", "d"}}, mock.exported())
+	assert.Equal(t, []sensor.Data{{Name: "b"}}, exporter.Failed(nil, err))
+}
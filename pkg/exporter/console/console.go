@@ -24,6 +24,24 @@ func (e Exporter) Export(ctx context.Context, data sensor.Data) error {
 	return nil
 }
 
+func (e Exporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	j, err := json.MarshalIndent(event, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(j))
+	return nil
+}
+
+func (e Exporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	j, err := json.MarshalIndent(agg, "", "    ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(j))
+	return nil
+}
+
 func (e Exporter) Close() error {
 	return nil
 }
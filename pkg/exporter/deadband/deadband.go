@@ -0,0 +1,146 @@
+package deadband
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// tolerance absorbs floating point errors in the difference of decimal sensor readings
+const tolerance = 1e-9
+
+// Exporter forwards a measurement of a sensor to the wrapped exporter only when a field has
+// moved at least its threshold from the previously forwarded measurement, or when Heartbeat
+// has passed since the previously forwarded measurement. Events and aggregates are always
+// forwarded if the wrapped exporter supports them.
+type Exporter struct {
+	Exporter exporter.Exporter
+	// Thresholds contains the deadband of each field by JSON name, such as "temperature".
+	// Changes in fields without a threshold do not cause a measurement to be forwarded.
+	Thresholds map[string]float64
+	// Heartbeat is the maximum time between forwarded measurements of a sensor, 0 to disable
+	Heartbeat time.Duration
+
+	mu   sync.Mutex
+	last map[string]sensor.Data
+}
+
+// New wraps the exporter in a deadband filter
+func New(exp exporter.Exporter, thresholds map[string]float64, heartbeat time.Duration) (*Exporter, error) {
+	for field, threshold := range thresholds {
+		if !sensor.IsField(field) {
+			return nil, fmt.Errorf("unknown field: %s", field)
+		}
+		if threshold < 0 {
+			return nil, fmt.Errorf("threshold of field %s must not be negative", field)
+		}
+	}
+	return &Exporter{
+		Exporter:   exp,
+		Thresholds: thresholds,
+		Heartbeat:  heartbeat,
+		last:       make(map[string]sensor.Data),
+	}, nil
+}
+
+func (e *Exporter) Name() string {
+	return e.Exporter.Name()
+}
+
+func (e *Exporter) Export(ctx context.Context, data sensor.Data) error {
+	e.mu.Lock()
+	prev, ok := e.last[data.Addr]
+	forward := !ok || e.changed(prev, data)
+	e.mu.Unlock()
+	if !forward {
+		return nil
+	}
+	if err := e.Exporter.Export(ctx, data); err != nil {
+		return err
+	}
+	e.record(map[string]sensor.Data{data.Addr: data})
+	return nil
+}
+
+// ExportBatch forwards the measurements of the batch that pass the deadband as a single batch
+func (e *Exporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	var changed []sensor.Data
+	last := make(map[string]sensor.Data)
+	e.mu.Lock()
+	for _, d := range data {
+		prev, ok := last[d.Addr]
+		if !ok {
+			prev, ok = e.last[d.Addr]
+		}
+		if !ok || e.changed(prev, d) {
+			last[d.Addr] = d
+			changed = append(changed, d)
+		}
+	}
+	e.mu.Unlock()
+	if len(changed) == 0 {
+		return nil
+	}
+	if err := exporter.ExportBatch(ctx, e.Exporter, changed); err != nil {
+		return err
+	}
+	e.record(last)
+	return nil
+}
+
+func (e *Exporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	if ee, ok := e.Exporter.(exporter.EventExporter); ok {
+		return ee.ExportEvent(ctx, event)
+	}
+	return nil
+}
+
+func (e *Exporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	if ae, ok := e.Exporter.(exporter.AggregateExporter); ok {
+		return ae.ExportAggregate(ctx, agg)
+	}
+	return nil
+}
+
+func (e *Exporter) Close() error {
+	return e.Exporter.Close()
+}
+
+// changed reports whether the measurement should be forwarded after the previously forwarded one
+func (e *Exporter) changed(prev, data sensor.Data) bool {
+	return e.heartbeat(prev, data) || e.moved(prev, data)
+}
+
+// record stores the measurements as the previously forwarded measurements of their sensors.
+// Measurements are recorded only after they have been exported so that a failed export does
+// not suppress the following measurements.
+func (e *Exporter) record(data map[string]sensor.Data) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for addr, d := range data {
+		e.last[addr] = d
+	}
+}
+
+func (e *Exporter) heartbeat(prev, data sensor.Data) bool {
+	return e.Heartbeat > 0 && data.Timestamp.Sub(prev.Timestamp) >= e.Heartbeat
+}
+
+func (e *Exporter) moved(prev, data sensor.Data) bool {
+	for field, threshold := range e.Thresholds {
+		pv, pok := prev.Value(field)
+		v, ok := data.Value(field)
+		if pok != ok {
+			return true
+		}
+		if ok && v != pv && math.Abs(v-pv) >= threshold-tolerance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package deadband
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockExporter struct {
+	err    error
+	data   []sensor.Data
+	events []sensor.Event
+}
+
+func (m *mockExporter) Name() string {
+	return "Mock"
+}
+
+func (m *mockExporter) Export(ctx context.Context, data sensor.Data) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.data = append(m.data, data)
+	return nil
+}
+
+func (m *mockExporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockExporter) Close() error {
+	return nil
+}
+
+func TestExport(t *testing.T) {
+	mock := new(mockExporter)
+	exp, err := New(mock, map[string]float64{"temperature": 0.1, "humidity": 1}, 5*time.Minute)
+	require.NoError(t, err)
+	tests := []struct {
+		data      sensor.Data
+		forwarded bool
+	}{
+		{sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.5), Humidity: sensor.Float64(60), Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)}, true},
+		{sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.55), Humidity: sensor.Float64(60.5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 1, 0, time.UTC)}, false},
+		{sensor.Data{Addr: "fb:e1:b7:04:95:ee", Temperature: sensor.Float64(18), Humidity: sensor.Float64(40), Timestamp: time.Date(2020, time.January, 1, 12, 0, 1, 0, time.UTC)}, true},
+		{sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.6), Humidity: sensor.Float64(60.5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 2, 0, time.UTC)}, true},
+		{sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.6), Humidity: sensor.Float64(61.5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 3, 0, time.UTC)}, true},
+		{sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.6), Humidity: sensor.Float64(61.5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 4, 0, time.UTC)}, false},
+		// Unchanged measurements are forwarded after the heartbeat interval
+		{sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.6), Humidity: sensor.Float64(61.5), Timestamp: time.Date(2020, time.January, 1, 12, 5, 3, 0, time.UTC)}, true},
+	}
+	for i, tt := range tests {
+		mock.data = nil
+		require.NoError(t, exp.Export(context.Background(), tt.data))
+		if tt.forwarded {
+			assert.Equal(t, []sensor.Data{tt.data}, mock.data, "measurement %d", i)
+		} else {
+			assert.Empty(t, mock.data, "measurement %d", i)
+		}
+	}
+
+	require.NoError(t, exp.ExportEvent(context.Background(), sensor.Event{Type: sensor.EventMotion}))
+	assert.Len(t, mock.events, 1)
+	assert.Equal(t, "Mock", exp.Name())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(new(mockExporter), map[string]float64{"temprature": 0.1}, 0)
+	assert.Error(t, err)
+	_, err = New(new(mockExporter), map[string]float64{"temperature": -1}, 0)
+	assert.Error(t, err)
+}
+
+func TestExportBatch(t *testing.T) {
+	mock := new(mockExporter)
+	exp, err := New(mock, map[string]float64{"temperature": 0.1}, 0)
+	require.NoError(t, err)
+	measurements := []sensor.Data{
+		{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)},
+		{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.55), Timestamp: time.Date(2020, time.January, 1, 12, 0, 1, 0, time.UTC)},
+		{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.6), Timestamp: time.Date(2020, time.January, 1, 12, 0, 2, 0, time.UTC)},
+	}
+	require.NoError(t, exp.ExportBatch(context.Background(), measurements))
+	assert.Equal(t, []sensor.Data{measurements[0], measurements[2]}, mock.data)
+}
+
+func TestExportFailed(t *testing.T) {
+	mock := &mockExporter{err: errors.New("connection refused")}
+	exp, err := New(mock, map[string]float64{"temperature": 0.1}, 0)
+	require.NoError(t, err)
+	m := sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	assert.Error(t, exp.Export(context.Background(), m))
+	assert.Error(t, exp.ExportBatch(context.Background(), []sensor.Data{m}))
+	// Measurements that failed to export are not recorded as forwarded
+	mock.err = nil
+	require.NoError(t, exp.Export(context.Background(), m))
+	require.NoError(t, exp.Export(context.Background(), sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(21.55), Timestamp: time.Date(2020, time.January, 1, 12, 0, 1, 0, time.UTC)}))
+	assert.Equal(t, []sensor.Data{m}, mock.data)
+}
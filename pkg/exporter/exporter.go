@@ -2,6 +2,8 @@ package exporter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
@@ -11,3 +13,61 @@ type Exporter interface {
 	Export(ctx context.Context, data sensor.Data) error
 	Close() error
 }
+
+// EventExporter is implemented by exporters that can also export events
+type EventExporter interface {
+	ExportEvent(ctx context.Context, event sensor.Event) error
+}
+
+// AggregateExporter is implemented by exporters that can also export aggregated measurements
+type AggregateExporter interface {
+	ExportAggregate(ctx context.Context, agg sensor.Aggregate) error
+}
+
+// BatchExporter is implemented by exporters that can export several measurements at once
+type BatchExporter interface {
+	ExportBatch(ctx context.Context, data []sensor.Data) error
+}
+
+// BatchError is returned by ExportBatch when some of the measurements of a batch may have
+// been exported and the rest were not
+type BatchError struct {
+	// Failed contains the measurements that were not exported
+	Failed []sensor.Data
+	Err    error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("failed to export %d measurements: %v", len(e.Failed), e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Failed returns the measurements of the batch that were not exported because of err
+func Failed(data []sensor.Data, err error) []sensor.Data {
+	if err == nil {
+		return nil
+	}
+	var be *BatchError
+	if errors.As(err, &be) {
+		return be.Failed
+	}
+	return data
+}
+
+// ExportBatch exports the measurements in a single batch if the exporter supports batches
+// and otherwise one by one, stopping at the first error. The measurements left unexported
+// by an error are returned in a BatchError.
+func ExportBatch(ctx context.Context, e Exporter, data []sensor.Data) error {
+	if be, ok := e.(BatchExporter); ok {
+		return be.ExportBatch(ctx, data)
+	}
+	for i, d := range data {
+		if err := e.Export(ctx, d); err != nil {
+			return &BatchError{Failed: data[i:], Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockExporter struct {
+	reject string
+	data   []sensor.Data
+}
+
+func (m *mockExporter) Name() string {
+	return "Mock"
+}
+
+func (m *mockExporter) Export(ctx context.Context, data sensor.Data) error {
+	if data.Name == m.reject {
+		return errors.New("connection refused")
+	}
+	m.data = append(m.data, data)
+	return nil
+}
+
+func (m *mockExporter) Close() error {
+	return nil
+}
+
+func TestExportBatch(t *testing.T) {
+	data := []sensor.Data{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	mock := &mockExporter{reject: "b"}
+	err := ExportBatch(context.Background(), mock, data)
+	require.Error(t, err)
+	assert.Equal(t, data[:1], mock.data)
+	assert.Equal(t, data[1:], Failed(data, err))
+	assert.EqualError(t, errors.Unwrap(err), "connection refused")
+
+	mock = new(mockExporter)
+	require.NoError(t, ExportBatch(context.Background(), mock, data))
+	assert.Equal(t, data, mock.data)
+}
+
+func TestFailed(t *testing.T) {
+	data := []sensor.Data{{Name: "a"}, {Name: "b"}}
+	assert.Nil(t, Failed(data, nil))
+	assert.Equal(t, data, Failed(data, errors.New("connection refused")))
+}
@@ -57,6 +57,25 @@ func (e *pubsubExporter) Export(ctx context.Context, data sensor.Data) error {
 	return err
 }
 
+// ExportAggregate publishes the aggregate with the type attribute set to aggregate
+func (e *pubsubExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	agg.Addr = strings.ToUpper(agg.Addr)
+	jsonData, err := json.Marshal(agg)
+	if err != nil {
+		return err
+	}
+	msg := &pubsub.Message{
+		Data: jsonData,
+		Attributes: map[string]string{
+			"mac":  agg.Addr,
+			"name": agg.Name,
+			"type": "aggregate",
+		},
+	}
+	_, err = e.topic.Publish(ctx, msg).Get(ctx)
+	return err
+}
+
 func (e *pubsubExporter) Close() error {
 	e.topic.Stop()
 	return e.client.Close()
@@ -24,13 +24,13 @@ func TestPublish(t *testing.T) {
 	err = e.Export(ctx, sensor.Data{
 		Addr:           "CC:CA:7E:52:CC:34",
 		Name:           "TestRuuviTag",
-		Temperature:    20.1,
-		Humidity:       65,
-		Pressure:       1001,
+		Temperature:    sensor.Float64(20.1),
+		Humidity:       sensor.Float64(65),
+		Pressure:       sensor.Float64(1001),
 		BatteryVoltage: 50,
-		AccelerationX:  0,
-		AccelerationY:  0,
-		AccelerationZ:  0,
+		AccelerationX:  sensor.Int(0),
+		AccelerationY:  sensor.Int(0),
+		AccelerationZ:  sensor.Int(0),
 		Timestamp:      time.Now(),
 	})
 	require.NoError(t, err)
@@ -37,9 +37,23 @@ func (h httpExporter) Name() string {
 }
 
 func (h httpExporter) Export(ctx context.Context, data sensor.Data) error {
+	return h.post(ctx, data)
+}
+
+// ExportBatch posts the measurements as a JSON array
+func (h httpExporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	return h.post(ctx, data)
+}
+
+// ExportAggregate posts the aggregate as JSON to the same endpoint as measurements
+func (h httpExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	return h.post(ctx, agg)
+}
+
+func (h httpExporter) post(ctx context.Context, v interface{}) error {
 	buf := new(bytes.Buffer)
 	enc := json.NewEncoder(buf)
-	err := enc.Encode(data)
+	err := enc.Encode(v)
 	if err != nil {
 		return err
 	}
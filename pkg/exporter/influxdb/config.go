@@ -1,12 +1,13 @@
 package influxdb
 
 type Config struct {
-	Addr        string
-	Org         string
-	Bucket      string
-	Database    string
-	Measurement string
-	Token       string
-	Username    string
-	Password    string
+	Addr                 string
+	Org                  string
+	Bucket               string
+	Database             string
+	Measurement          string
+	AggregateMeasurement string
+	Token                string
+	Username             string
+	Password             string
 }
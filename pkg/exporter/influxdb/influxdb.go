@@ -9,15 +9,17 @@ import (
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
 
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
 
 type influxdbExporter struct {
-	client      influxdb2.Client
-	writeAPI    api.WriteAPIBlocking
-	measurement string
+	client               influxdb2.Client
+	writeAPI             api.WriteAPIBlocking
+	measurement          string
+	aggregateMeasurement string
 }
 
 func New(cfg Config) exporter.Exporter {
@@ -31,10 +33,15 @@ func New(cfg Config) exporter.Exporter {
 		bucket = cfg.Database
 	}
 	writeAPI := client.WriteAPIBlocking(cfg.Org, bucket)
+	aggregateMeasurement := cfg.AggregateMeasurement
+	if aggregateMeasurement == "" {
+		aggregateMeasurement = cfg.Measurement + "_aggregate"
+	}
 	return &influxdbExporter{
-		client:      client,
-		writeAPI:    writeAPI,
-		measurement: cfg.Measurement,
+		client:               client,
+		writeAPI:             writeAPI,
+		measurement:          cfg.Measurement,
+		aggregateMeasurement: aggregateMeasurement,
 	}
 }
 
@@ -43,25 +50,136 @@ func (e *influxdbExporter) Name() string {
 }
 
 func (e *influxdbExporter) Export(ctx context.Context, data sensor.Data) error {
-	point := influxdb2.NewPoint(e.measurement, map[string]string{
+	return e.writeAPI.WritePoint(ctx, e.point(data))
+}
+
+// ExportBatch writes the measurements as points in a single request
+func (e *influxdbExporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	points := make([]*write.Point, len(data))
+	for i, d := range data {
+		points[i] = e.point(d)
+	}
+	return e.writeAPI.WritePoint(ctx, points...)
+}
+
+func (e *influxdbExporter) point(data sensor.Data) *write.Point {
+	fields := map[string]interface{}{
+		"battery_voltage":  data.BatteryVoltage,
+		"tx_power":         data.TxPower,
+		"movement_counter": data.MovementCounter,
+	}
+	addFloatField(fields, "battery_level", data.BatteryLevel)
+	addFloatField(fields, "temperature", data.Temperature)
+	addFloatField(fields, "humidity", data.Humidity)
+	addFloatField(fields, "dew_point", data.DewPoint)
+	addFloatField(fields, "condensation_margin", data.CondensationMargin)
+	if data.CondensationRisk != nil {
+		fields["condensation_risk"] = *data.CondensationRisk
+	}
+	addFloatField(fields, "mold_index", data.MoldIndex)
+	addFloatField(fields, "pressure", data.Pressure)
+	addFloatField(fields, "sea_level_pressure", data.SeaLevelPressure)
+	addFloatField(fields, "pressure_tendency", data.PressureTendency)
+	addIntField(fields, "pressure_tendency_code", data.PressureTendencyCode)
+	if data.PressureTrend != "" {
+		fields["pressure_trend"] = data.PressureTrend
+	}
+	if data.Forecast != "" {
+		fields["forecast"] = data.Forecast
+	}
+	addIntField(fields, "forecast_code", data.ForecastCode)
+	addIntField(fields, "acceleration_x", data.AccelerationX)
+	addIntField(fields, "acceleration_y", data.AccelerationY)
+	addIntField(fields, "acceleration_z", data.AccelerationZ)
+	addFloatField(fields, "pitch", data.Pitch)
+	addFloatField(fields, "roll", data.Roll)
+	addFloatField(fields, "g_force", data.GForce)
+	if data.OrientationChanged != nil {
+		fields["orientation_changed"] = *data.OrientationChanged
+	}
+	addIntField(fields, "measurement_number", data.MeasurementNumber)
+	addFloatField(fields, "pm1_0", data.PM1)
+	addFloatField(fields, "pm2_5", data.PM25)
+	addFloatField(fields, "pm4_0", data.PM4)
+	addFloatField(fields, "pm10_0", data.PM10)
+	addIntField(fields, "co2", data.CO2)
+	addIntField(fields, "voc_index", data.VOCIndex)
+	addIntField(fields, "nox_index", data.NOXIndex)
+	addFloatField(fields, "luminosity", data.Luminosity)
+	addFloatField(fields, "absolute_humidity", data.AbsoluteHumidity)
+	addFloatField(fields, "mixing_ratio", data.MixingRatio)
+	addFloatField(fields, "vapor_pressure_deficit", data.VPD)
+	addFloatField(fields, "wet_bulb", data.WetBulb)
+	addFloatField(fields, "frost_point", data.FrostPoint)
+	addFloatField(fields, "heat_index", data.HeatIndex)
+	addFloatField(fields, "humidex", data.Humidex)
+	if data.RSSI != 0 {
+		fields["rssi"] = data.RSSI
+	}
+	tags := map[string]string{
 		"mac":  strings.ToUpper(data.Addr),
 		"name": data.Name,
-	}, map[string]interface{}{
-		"temperature":        data.Temperature,
-		"humidity":           data.Humidity,
-		"dew_point":          data.DewPoint,
-		"pressure":           data.Pressure,
-		"battery_voltage":    data.BatteryVoltage,
-		"tx_power":           data.TxPower,
-		"acceleration_x":     data.AccelerationX,
-		"acceleration_y":     data.AccelerationY,
-		"acceleration_z":     data.AccelerationZ,
-		"movement_counter":   data.MovementCounter,
-		"measurement_number": data.MeasurementNumber,
-	}, data.Timestamp)
+	}
+	if data.Gateway != "" {
+		tags["gateway"] = data.Gateway
+	}
+	if data.LocalName != "" {
+		tags["local_name"] = data.LocalName
+	}
+	return influxdb2.NewPoint(e.measurement, tags, fields, data.Timestamp)
+}
+
+// ExportEvent writes the event as a point to a measurement named after the event type
+func (e *influxdbExporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	tags := map[string]string{
+		"mac":  strings.ToUpper(event.Addr),
+		"name": event.Name,
+	}
+	fields := event.Fields
+	if len(fields) == 0 {
+		fields = map[string]interface{}{"count": 1}
+	}
+	point := influxdb2.NewPoint(event.Type, tags, fields, event.Timestamp)
+	return e.writeAPI.WritePoint(ctx, point)
+}
+
+// ExportAggregate writes the aggregate as a point at the end of the window. The statistics
+// of each field are stored in fields such as temperature_mean and temperature_max.
+func (e *influxdbExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	tags := map[string]string{
+		"mac":  strings.ToUpper(agg.Addr),
+		"name": agg.Name,
+	}
+	if agg.Gateway != "" {
+		tags["gateway"] = agg.Gateway
+	}
+	fields := map[string]interface{}{
+		"count": agg.Count,
+	}
+	for name, s := range agg.Fields {
+		fields[name+"_min"] = s.Min
+		fields[name+"_max"] = s.Max
+		fields[name+"_mean"] = s.Mean
+		fields[name+"_median"] = s.Median
+		fields[name+"_stddev"] = s.StdDev
+		fields[name+"_last"] = s.Last
+	}
+	point := influxdb2.NewPoint(e.aggregateMeasurement, tags, fields, agg.End)
 	return e.writeAPI.WritePoint(ctx, point)
 }
 
+func addFloatField(fields map[string]interface{}, name string, value *float64) {
+	if value != nil {
+		fields[name] = *value
+	}
+}
+
+func addIntField(fields map[string]interface{}, name string, value *int) {
+	if value != nil {
+		fields[name] = *value
+	}
+}
+
 func (e *influxdbExporter) Close() error {
 	e.client.Close()
 	return nil
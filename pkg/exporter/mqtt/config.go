@@ -6,7 +6,7 @@ import (
 
 type Config struct {
 	Addr              string
-	Topic			  string
+	Topic             string
 	ClientId          string
 	Username          string
 	Password          string
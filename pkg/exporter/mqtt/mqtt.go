@@ -9,21 +9,35 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"io/ioutil"
 	"strings"
 
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
 
 type mqttExporter struct {
-	client    mqtt.Client
-	topic	  string
-	tlsConfig *tls.Config
+	client mqtt.Client
+	topic  string
 }
 
 func New(cfg Config) (exporter.Exporter, error) {
+	client, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Topic == "" {
+		cfg.Topic = "ruuvitag-gollector"
+	}
+	return &mqttExporter{
+		client: client,
+		topic:  cfg.Topic,
+	}, nil
+}
+
+func connect(cfg Config) (mqtt.Client, error) {
 	opts := mqtt.NewClientOptions()
 	opts.AddBroker(cfg.Addr)
 	opts.SetClientID(cfg.ClientId)
@@ -44,14 +58,7 @@ func New(cfg Config) (exporter.Exporter, error) {
 	if token := client.Connect(); token.Wait() && token.Error() != nil {
 		return nil, token.Error()
 	}
-	if cfg.Topic != "" {
-		cfg.Topic = "ruuvitag-gollector"
-	}
-	return &mqttExporter{
-		client:    client,
-		topic: cfg.Topic,
-		tlsConfig: tlsConfig,
-	}, nil
+	return client, nil
 }
 
 func newTlsConfig(cfg Config) (*tls.Config, error) {
@@ -74,14 +81,23 @@ func (m mqttExporter) Name() string {
 }
 
 func (m mqttExporter) Export(ctx context.Context, data sensor.Data) error {
+	mac := strings.Replace(data.Addr, ":", "", -1)
+	return m.publish(fmt.Sprintf("%s/%s/%s", m.topic, data.Name, mac), data)
+}
+
+// ExportAggregate publishes the aggregate to the aggregate subtopic of the sensor
+func (m mqttExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	mac := strings.Replace(agg.Addr, ":", "", -1)
+	return m.publish(fmt.Sprintf("%s/%s/%s/aggregate", m.topic, agg.Name, mac), agg)
+}
+
+func (m mqttExporter) publish(topic string, v interface{}) error {
 	buf := new(bytes.Buffer)
 	enc := json.NewEncoder(buf)
-	err := enc.Encode(data)
+	err := enc.Encode(v)
 	if err != nil {
 		return err
 	}
-	mac := strings.Replace(data.Addr, ":", "", -1)
-	topic := fmt.Sprintf(m.client.topic+"%s/%s", data.Name, mac)
 	token := m.client.Publish(topic, 0, false, buf.String())
 	token.Wait()
 	return token.Error()
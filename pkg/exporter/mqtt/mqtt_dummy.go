@@ -6,6 +6,6 @@ import (
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 )
 
-func New(cfg Config) exporter.Exporter {
-	return exporter.NoOp{ReportedName: "MQTT"}
+func New(cfg Config) (exporter.Exporter, error) {
+	return exporter.NoOp{ReportedName: "MQTT"}, nil
 }
@@ -0,0 +1,52 @@
+// +build mqtt
+
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/notify"
+)
+
+type mqttNotifier struct {
+	client mqtt.Client
+	topic  string
+}
+
+// NewNotifier creates a notifier publishing notifications as JSON to the given topic
+func NewNotifier(cfg Config, topic string) (notify.Notifier, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("MQTT notification topic must be specified")
+	}
+	client, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &mqttNotifier{
+		client: client,
+		topic:  topic,
+	}, nil
+}
+
+func (m *mqttNotifier) Name() string {
+	return fmt.Sprintf("MQTT (%s)", m.topic)
+}
+
+func (m *mqttNotifier) Notify(ctx context.Context, n notify.Notification) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	token := m.client.Publish(m.topic, 1, false, payload)
+	token.Wait()
+	return token.Error()
+}
+
+func (m *mqttNotifier) Close() error {
+	m.client.Disconnect(250)
+	return nil
+}
@@ -5,7 +5,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
@@ -26,41 +29,187 @@ const SchemaTmpl = `CREATE TABLE %s (
   acceleration_z INTEGER,
   movement_counter INTEGER,
   battery REAL,
-  measurement_number INTEGER
+  measurement_number INTEGER,
+  pm1_0 REAL,
+  pm2_5 REAL,
+  pm4_0 REAL,
+  pm10_0 REAL,
+  co2 INTEGER,
+  voc_index INTEGER,
+  nox_index INTEGER,
+  luminosity REAL,
+  rssi INTEGER,
+  local_name TEXT,
+  gateway TEXT,
+  absolute_humidity REAL,
+  mixing_ratio REAL,
+  vapor_pressure_deficit REAL,
+  wet_bulb REAL,
+  frost_point REAL,
+  heat_index REAL,
+  humidex REAL,
+  sea_level_pressure REAL,
+  pitch REAL,
+  roll REAL,
+  g_force REAL,
+  orientation_changed BOOLEAN,
+  battery_level REAL,
+  condensation_margin REAL,
+  condensation_risk BOOLEAN,
+  mold_index REAL,
+  pressure_tendency REAL,
+  pressure_tendency_code INTEGER,
+  pressure_trend TEXT,
+  forecast TEXT,
+  forecast_code INTEGER
 )`
 
+const EventsSchemaTmpl = `CREATE TABLE %s (
+  id BIGSERIAL PRIMARY KEY,
+  mac MACADDR NOT NULL,
+  name TEXT,
+  ts TIMESTAMP NOT NULL,
+  type TEXT NOT NULL,
+  fields JSONB
+)`
+
+// AggregatesSchemaTmpl is the schema of the aggregates table. Each aggregated field is stored as its own row.
+const AggregatesSchemaTmpl = `CREATE TABLE %s (
+  id BIGSERIAL PRIMARY KEY,
+  mac MACADDR NOT NULL,
+  name TEXT,
+  gateway TEXT,
+  start_ts TIMESTAMP NOT NULL,
+  ts TIMESTAMP NOT NULL,
+  field TEXT NOT NULL,
+  count INTEGER NOT NULL,
+  min REAL,
+  max REAL,
+  mean REAL,
+  median REAL,
+  stddev REAL,
+  last REAL
+)`
+
+// maxParameters is the maximum number of parameters in a PostgreSQL statement
+const maxParameters = 65535
+
+// columns are the columns of the measurements table that are inserted
+var columns = []string{
+	"mac",
+	"name",
+	"ts",
+	"temperature",
+	"humidity",
+	"pressure",
+	"acceleration_x",
+	"acceleration_y",
+	"acceleration_z",
+	"movement_counter",
+	"battery",
+	"measurement_number",
+	"pm1_0",
+	"pm2_5",
+	"pm4_0",
+	"pm10_0",
+	"co2",
+	"voc_index",
+	"nox_index",
+	"luminosity",
+	"rssi",
+	"local_name",
+	"gateway",
+	"absolute_humidity",
+	"mixing_ratio",
+	"vapor_pressure_deficit",
+	"wet_bulb",
+	"frost_point",
+	"heat_index",
+	"humidex",
+	"sea_level_pressure",
+	"pitch",
+	"roll",
+	"g_force",
+	"orientation_changed",
+	"battery_level",
+	"condensation_margin",
+	"condensation_risk",
+	"mold_index",
+	"pressure_tendency",
+	"pressure_tendency_code",
+	"pressure_trend",
+	"forecast",
+	"forecast_code",
+}
+
+// insertQuery returns a statement inserting rows of measurements to the table
+func insertQuery(table string, rows int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s)\nVALUES ", table, strings.Join(columns, ", "))
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", i*len(columns)+j+1)
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
 type postgresExporter struct {
-	db         *sql.DB
-	insertStmt *sql.Stmt
+	db            *sql.DB
+	table         string
+	insertStmt    *sql.Stmt
+	eventStmt     *sql.Stmt
+	aggregateStmt *sql.Stmt
 }
 
-func New(ctx context.Context, connStr, table string) (exporter.Exporter, error) {
+// New creates a PostgreSQL exporter. Events are stored in eventsTable and aggregates
+// in aggregatesTable if they are not empty.
+func New(ctx context.Context, connStr, table, eventsTable, aggregatesTable string) (exporter.Exporter, error) {
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, err
 	}
-	insertStmt, err := db.PrepareContext(ctx, fmt.Sprintf(`
-INSERT INTO %s (
-  mac,
-  name,
-  ts,
-  temperature,
-  humidity,
-  pressure,
-  acceleration_x,
-  acceleration_y,
-  acceleration_z,
-  movement_counter,
-  battery,
-  measurement_number
-)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`, table))
+	insertStmt, err := db.PrepareContext(ctx, insertQuery(table, 1))
 	if err != nil {
 		return nil, err
 	}
+	var eventStmt *sql.Stmt
+	if eventsTable != "" {
+		eventStmt, err = db.PrepareContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (mac, name, ts, type, fields)
+VALUES ($1, $2, $3, $4, $5)`, eventsTable))
+		if err != nil {
+			insertStmt.Close()
+			return nil, err
+		}
+	}
+	var aggregateStmt *sql.Stmt
+	if aggregatesTable != "" {
+		aggregateStmt, err = db.PrepareContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (mac, name, gateway, start_ts, ts, field, count, min, max, mean, median, stddev, last)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`, aggregatesTable))
+		if err != nil {
+			insertStmt.Close()
+			if eventStmt != nil {
+				eventStmt.Close()
+			}
+			return nil, err
+		}
+	}
 	return &postgresExporter{
-		db:         db,
-		insertStmt: insertStmt,
+		db:            db,
+		table:         table,
+		insertStmt:    insertStmt,
+		eventStmt:     eventStmt,
+		aggregateStmt: aggregateStmt,
 	}, nil
 }
 
@@ -69,11 +218,141 @@ func (p *postgresExporter) Name() string {
 }
 
 func (p *postgresExporter) Export(ctx context.Context, data sensor.Data) error {
-	_, err := p.insertStmt.ExecContext(ctx, data.Addr, data.Name, data.Timestamp, data.Temperature, data.Humidity, data.Pressure, data.AccelerationX, data.AccelerationY, data.AccelerationZ, data.MovementCounter, data.BatteryVoltage, data.MeasurementNumber)
+	_, err := p.insertStmt.ExecContext(ctx, values(data)...)
 	return err
 }
 
+// ExportBatch inserts the measurements with multi-row inserts. The measurements of the failed
+// insert and the ones after it are returned in an exporter.BatchError.
+func (p *postgresExporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	maxRows := maxParameters / len(columns)
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxRows {
+			n = maxRows
+		}
+		var args []interface{}
+		for _, d := range data[:n] {
+			args = append(args, values(d)...)
+		}
+		if _, err := p.db.ExecContext(ctx, insertQuery(p.table, n), args...); err != nil {
+			return &exporter.BatchError{Failed: data, Err: err}
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// values returns the values of the measurement in the order of columns
+func values(data sensor.Data) []interface{} {
+	return []interface{}{
+		data.Addr,
+		data.Name,
+		data.Timestamp,
+		data.Temperature,
+		data.Humidity,
+		data.Pressure,
+		data.AccelerationX,
+		data.AccelerationY,
+		data.AccelerationZ,
+		data.MovementCounter,
+		data.BatteryVoltage,
+		data.MeasurementNumber,
+		data.PM1,
+		data.PM25,
+		data.PM4,
+		data.PM10,
+		data.CO2,
+		data.VOCIndex,
+		data.NOXIndex,
+		data.Luminosity,
+		sql.NullInt32{Int32: int32(data.RSSI), Valid: data.RSSI != 0},
+		sql.NullString{String: data.LocalName, Valid: data.LocalName != ""},
+		sql.NullString{String: data.Gateway, Valid: data.Gateway != ""},
+		data.AbsoluteHumidity,
+		data.MixingRatio,
+		data.VPD,
+		data.WetBulb,
+		data.FrostPoint,
+		data.HeatIndex,
+		data.Humidex,
+		data.SeaLevelPressure,
+		data.Pitch,
+		data.Roll,
+		data.GForce,
+		data.OrientationChanged,
+		data.BatteryLevel,
+		data.CondensationMargin,
+		data.CondensationRisk,
+		data.MoldIndex,
+		data.PressureTendency,
+		data.PressureTendencyCode,
+		sql.NullString{String: data.PressureTrend, Valid: data.PressureTrend != ""},
+		sql.NullString{String: data.Forecast, Valid: data.Forecast != ""},
+		data.ForecastCode,
+	}
+}
+
+func (p *postgresExporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	if p.eventStmt == nil {
+		return nil
+	}
+	fields, err := json.Marshal(event.Fields)
+	if err != nil {
+		return err
+	}
+	_, err = p.eventStmt.ExecContext(ctx, event.Addr, event.Name, event.Timestamp, event.Type, fields)
+	return err
+}
+
+// ExportAggregate stores the statistics of each aggregated field as a row in a single transaction
+func (p *postgresExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	if p.aggregateStmt == nil {
+		return nil
+	}
+	var fields []string
+	for field := range agg.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmt := tx.StmtContext(ctx, p.aggregateStmt)
+	for _, field := range fields {
+		s := agg.Fields[field]
+		_, err = stmt.ExecContext(
+			ctx,
+			agg.Addr,
+			agg.Name,
+			sql.NullString{String: agg.Gateway, Valid: agg.Gateway != ""},
+			agg.Start,
+			agg.End,
+			field,
+			s.Count,
+			s.Min,
+			s.Max,
+			s.Mean,
+			s.Median,
+			s.StdDev,
+			s.Last,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (p *postgresExporter) Close() error {
 	p.insertStmt.Close()
+	if p.eventStmt != nil {
+		p.eventStmt.Close()
+	}
+	if p.aggregateStmt != nil {
+		p.aggregateStmt.Close()
+	}
 	return p.db.Close()
 }
@@ -0,0 +1,252 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Policy decides what happens when a measurement is exported to a full queue
+type Policy string
+
+// Overflow policies
+const (
+	// Block waits until the queue has room or the export context is done
+	Block Policy = "block"
+	// DropOldest drops the oldest queued item to make room
+	DropOldest Policy = "drop_oldest"
+	// DropNewest drops the item being exported
+	DropNewest Policy = "drop_newest"
+)
+
+// ParsePolicy parses an overflow policy name
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case Block, DropOldest, DropNewest:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported overflow policy: %s", s)
+	}
+}
+
+// Stats contains the counters of a queue. Each measurement of a queued batch is counted
+// separately.
+type Stats struct {
+	Queued   int `json:"queued"`
+	Exported int `json:"exported"`
+	Failed   int `json:"failed"`
+	Dropped  int `json:"dropped"`
+}
+
+type item struct {
+	data  *sensor.Data
+	batch []sensor.Data
+	event *sensor.Event
+	agg   *sensor.Aggregate
+}
+
+// count returns the number of measurements, events or aggregates in the item
+func (it item) count() int {
+	if it.batch != nil {
+		return len(it.batch)
+	}
+	return 1
+}
+
+// Exporter exports measurements, events and aggregates to the wrapped exporter from a bounded
+// queue in its own goroutine so that a slow or failing exporter does not delay the others.
+// Export errors are logged and counted instead of returned.
+type Exporter struct {
+	exp     exporter.Exporter
+	timeout time.Duration
+	policy  Policy
+	logger  *zap.Logger
+	items   chan item
+	quit    chan struct{}
+	done    chan struct{}
+
+	mu      sync.Mutex
+	closed  bool
+	stats   Stats
+	senders sync.WaitGroup
+}
+
+// New wraps the exporter in a queue of the given size. Each export to the wrapped exporter
+// is given the timeout.
+func New(exp exporter.Exporter, size int, timeout time.Duration, policy Policy, logger *zap.Logger) *Exporter {
+	e := &Exporter{
+		exp:     exp,
+		timeout: timeout,
+		policy:  policy,
+		logger:  logger,
+		items:   make(chan item, size),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go e.work()
+	return e
+}
+
+func (e *Exporter) Name() string {
+	return e.exp.Name()
+}
+
+func (e *Exporter) Export(ctx context.Context, data sensor.Data) error {
+	return e.enqueue(ctx, item{data: &data})
+}
+
+// ExportBatch queues the measurements as a single item that is exported as a batch
+func (e *Exporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return e.enqueue(ctx, item{batch: data})
+}
+
+func (e *Exporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	if _, ok := e.exp.(exporter.EventExporter); !ok {
+		return nil
+	}
+	return e.enqueue(ctx, item{event: &event})
+}
+
+func (e *Exporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	if _, ok := e.exp.(exporter.AggregateExporter); !ok {
+		return nil
+	}
+	return e.enqueue(ctx, item{agg: &agg})
+}
+
+// Close waits until the queued items have been exported and closes the wrapped exporter
+func (e *Exporter) Close() error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.quit)
+	}
+	e.mu.Unlock()
+	<-e.done
+	return e.exp.Close()
+}
+
+// Stats returns the counters of the queue
+func (e *Exporter) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.stats
+	s.Queued = len(e.items)
+	return s
+}
+
+func (e *Exporter) enqueue(ctx context.Context, it item) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return fmt.Errorf("exporter %s is closed", e.exp.Name())
+	}
+	select {
+	case e.items <- it:
+		return nil
+	default:
+	}
+	switch e.policy {
+	case DropNewest:
+		e.drop(it, "newest")
+		return nil
+	case DropOldest:
+		select {
+		case old := <-e.items:
+			e.drop(old, "oldest")
+		default:
+		}
+		select {
+		case e.items <- it:
+		default:
+			e.drop(it, "newest")
+		}
+		return nil
+	default:
+		// The lock is released while blocking so that the worker can update the counters.
+		// The worker waits for the blocked senders before its final drain on close.
+		e.senders.Add(1)
+		e.mu.Unlock()
+		defer e.mu.Lock()
+		defer e.senders.Done()
+		select {
+		case e.items <- it:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.quit:
+			return fmt.Errorf("exporter %s is closed", e.exp.Name())
+		}
+	}
+}
+
+func (e *Exporter) drop(it item, which string) {
+	e.stats.Dropped += it.count()
+	e.logger.Warn("Export queue full, dropping item", zap.String("exporter", e.exp.Name()), zap.String("dropped", which), zap.Int("count", it.count()))
+}
+
+func (e *Exporter) work() {
+	defer close(e.done)
+	for {
+		select {
+		case it := <-e.items:
+			e.process(it)
+		case <-e.quit:
+			e.senders.Wait()
+			for {
+				select {
+				case it := <-e.items:
+					e.process(it)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *Exporter) process(it item) {
+	err := e.export(it)
+	failed := 0
+	if err != nil {
+		failed = it.count()
+		if it.batch != nil {
+			failed = len(exporter.Failed(it.batch, err))
+		}
+	}
+	e.mu.Lock()
+	e.stats.Failed += failed
+	e.stats.Exported += it.count() - failed
+	e.mu.Unlock()
+	if err != nil {
+		e.logger.Error("Failed to export", zap.String("exporter", e.exp.Name()), zap.Error(err))
+	}
+}
+
+func (e *Exporter) export(it item) error {
+	ctx := context.Background()
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	switch {
+	case it.data != nil:
+		return e.exp.Export(ctx, *it.data)
+	case it.batch != nil:
+		return exporter.ExportBatch(ctx, e.exp, it.batch)
+	case it.event != nil:
+		return e.exp.(exporter.EventExporter).ExportEvent(ctx, *it.event)
+	default:
+		return e.exp.(exporter.AggregateExporter).ExportAggregate(ctx, *it.agg)
+	}
+}
@@ -0,0 +1,189 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockExporter struct {
+	mu      sync.Mutex
+	gate    chan struct{}
+	err     error
+	reject  string
+	data    []sensor.Data
+	events  []sensor.Event
+	started chan struct{}
+}
+
+func newMockExporter() *mockExporter {
+	return &mockExporter{started: make(chan struct{}, 100)}
+}
+
+func (m *mockExporter) Name() string {
+	return "Mock"
+}
+
+func (m *mockExporter) Export(ctx context.Context, data sensor.Data) error {
+	m.started <- struct{}{}
+	if m.gate != nil {
+		<-m.gate
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if data.Name == m.reject {
+		return errors.New("rejected")
+	}
+	m.data = append(m.data, data)
+	return m.err
+}
+
+func (m *mockExporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockExporter) Close() error {
+	return nil
+}
+
+func (m *mockExporter) names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for _, d := range m.data {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+func export(t *testing.T, exp *Exporter, names ...string) {
+	for _, name := range names {
+		require.NoError(t, exp.Export(context.Background(), sensor.Data{Name: name}))
+	}
+}
+
+func TestExport(t *testing.T) {
+	mock := newMockExporter()
+	exp := New(mock, 10, time.Second, Block, zap.NewNop())
+	export(t, exp, "a", "b", "c")
+	require.NoError(t, exp.ExportEvent(context.Background(), sensor.Event{Type: sensor.EventOffline}))
+	require.NoError(t, exp.Close())
+	assert.Equal(t, []string{"a", "b", "c"}, mock.names())
+	assert.Len(t, mock.events, 1)
+	assert.Equal(t, Stats{Exported: 4}, exp.Stats())
+}
+
+func TestExportErrors(t *testing.T) {
+	mock := newMockExporter()
+	mock.err = errors.New("export failed")
+	exp := New(mock, 10, time.Second, Block, zap.NewNop())
+	export(t, exp, "a", "b")
+	require.NoError(t, exp.Close())
+	assert.Equal(t, Stats{Failed: 2}, exp.Stats())
+}
+
+func TestExportBatch(t *testing.T) {
+	mock := newMockExporter()
+	mock.reject = "b"
+	exp := New(mock, 10, time.Second, Block, zap.NewNop())
+	require.NoError(t, exp.ExportBatch(context.Background(), []sensor.Data{{Name: "a"}, {Name: "b"}, {Name: "c"}}))
+	require.NoError(t, exp.ExportBatch(context.Background(), []sensor.Data{{Name: "d"}, {Name: "e"}}))
+	require.NoError(t, exp.Close())
+	assert.Equal(t, []string{"a", "d", "e"}, mock.names())
+	// Measurements of a batch are counted separately
+	assert.Equal(t, Stats{Exported: 3, Failed: 2}, exp.Stats())
+}
+
+func TestDropNewest(t *testing.T) {
+	mock := newMockExporter()
+	mock.gate = make(chan struct{})
+	exp := New(mock, 2, time.Second, DropNewest, zap.NewNop())
+	export(t, exp, "a")
+	<-mock.started
+	export(t, exp, "b", "c", "d")
+	assert.Equal(t, Stats{Queued: 2, Dropped: 1}, exp.Stats())
+	close(mock.gate)
+	require.NoError(t, exp.Close())
+	assert.Equal(t, []string{"a", "b", "c"}, mock.names())
+}
+
+func TestDropOldest(t *testing.T) {
+	mock := newMockExporter()
+	mock.gate = make(chan struct{})
+	exp := New(mock, 2, time.Second, DropOldest, zap.NewNop())
+	export(t, exp, "a")
+	<-mock.started
+	export(t, exp, "b", "c", "d")
+	assert.Equal(t, Stats{Queued: 2, Dropped: 1}, exp.Stats())
+	close(mock.gate)
+	require.NoError(t, exp.Close())
+	assert.Equal(t, []string{"a", "c", "d"}, mock.names())
+}
+
+func TestBlock(t *testing.T) {
+	mock := newMockExporter()
+	mock.gate = make(chan struct{})
+	exp := New(mock, 1, time.Second, Block, zap.NewNop())
+	export(t, exp, "a")
+	<-mock.started
+	export(t, exp, "b")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, exp.Export(ctx, sensor.Data{Name: "c"}))
+	close(mock.gate)
+	require.NoError(t, exp.Close())
+	assert.Equal(t, []string{"a", "b"}, mock.names())
+}
+
+func TestBlockDuringClose(t *testing.T) {
+	mock := newMockExporter()
+	mock.gate = make(chan struct{})
+	exp := New(mock, 1, time.Second, Block, zap.NewNop())
+	export(t, exp, "a")
+	<-mock.started
+	export(t, exp, "b")
+	errc := make(chan error, 1)
+	go func() {
+		errc <- exp.Export(context.Background(), sensor.Data{Name: "c"})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	closed := make(chan error, 1)
+	go func() {
+		closed <- exp.Close()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(mock.gate)
+	require.NoError(t, <-closed)
+	// A blocked sender either fails or gets its item exported before the queue closes
+	if err := <-errc; err == nil {
+		assert.Equal(t, []string{"a", "b", "c"}, mock.names())
+	} else {
+		assert.Equal(t, []string{"a", "b"}, mock.names())
+	}
+	assert.Equal(t, len(mock.names()), exp.Stats().Exported)
+}
+
+func TestExportAfterClose(t *testing.T) {
+	exp := New(newMockExporter(), 1, time.Second, Block, zap.NewNop())
+	require.NoError(t, exp.Close())
+	assert.Error(t, exp.Export(context.Background(), sensor.Data{}))
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("drop_oldest")
+	require.NoError(t, err)
+	assert.Equal(t, DropOldest, p)
+	_, err = ParsePolicy("drop_all")
+	assert.Error(t, err)
+}
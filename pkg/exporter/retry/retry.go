@@ -0,0 +1,284 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Config contains the settings of a retry buffer
+type Config struct {
+	Dir         string
+	SegmentSize int64
+	MaxSize     int64
+	MaxAge      time.Duration
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// Stats contains the depth and counters of a retry buffer
+type Stats struct {
+	Records  int   `json:"records"`
+	Bytes    int64 `json:"bytes"`
+	Replayed int   `json:"replayed"`
+	Expired  int   `json:"expired"`
+	Dropped  int   `json:"dropped"`
+}
+
+// Exporter stores measurements that the wrapped exporter fails to export in a buffer on disk
+// and replays them in order with exponential backoff. While the buffer is not empty, new
+// measurements are appended to it so that they are exported in order. When the buffer grows
+// over MaxSize, its oldest segment is dropped. Measurements buffered more than MaxAge ago are
+// dropped instead of being replayed. Events and aggregates are forwarded without buffering.
+type Exporter struct {
+	exp    exporter.Exporter
+	cfg    Config
+	logger *zap.Logger
+	wake   chan struct{}
+	quit   chan struct{}
+	done   chan struct{}
+
+	mu    sync.Mutex
+	store *store
+	stats Stats
+}
+
+// record is a buffered measurement with the time it was buffered. The age of a record is
+// based on the buffering time so that replayed recordings of old measurements do not expire.
+type record struct {
+	Buffered time.Time   `json:"buffered"`
+	Data     sensor.Data `json:"data"`
+}
+
+// New wraps the exporter in a retry buffer stored in cfg.Dir. Measurements left in the buffer
+// by a previous run are replayed.
+func New(exp exporter.Exporter, cfg Config, logger *zap.Logger) (*Exporter, error) {
+	if cfg.SegmentSize <= 0 {
+		cfg.SegmentSize = 1 << 20
+		// Leave room for several segments so that the size limit drops only a part of the buffer
+		if cfg.MaxSize > 0 && cfg.SegmentSize > cfg.MaxSize/4 {
+			cfg.SegmentSize = cfg.MaxSize/4 + 1
+		}
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = cfg.MinBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	s, err := openStore(cfg.Dir, cfg.SegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	e := &Exporter{
+		exp:    exp,
+		cfg:    cfg,
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+		store:  s,
+	}
+	go e.replay()
+	return e, nil
+}
+
+func (e *Exporter) Name() string {
+	return e.exp.Name()
+}
+
+func (e *Exporter) Export(ctx context.Context, data sensor.Data) error {
+	e.mu.Lock()
+	pending := e.store.pending() > 0
+	e.mu.Unlock()
+	if !pending {
+		err := e.exp.Export(ctx, data)
+		if err == nil {
+			return nil
+		}
+		e.logger.Warn("Failed to export, buffering measurement for retry", zap.String("exporter", e.exp.Name()), zap.Error(err))
+	}
+	return e.buffer(data, time.Now())
+}
+
+// ExportBatch exports the measurements as a single batch if the buffer is empty. If the
+// export fails, the measurements of the batch that were not exported are buffered.
+func (e *Exporter) ExportBatch(ctx context.Context, data []sensor.Data) error {
+	e.mu.Lock()
+	pending := e.store.pending() > 0
+	e.mu.Unlock()
+	if !pending {
+		err := exporter.ExportBatch(ctx, e.exp, data)
+		if err == nil {
+			return nil
+		}
+		data = exporter.Failed(data, err)
+		e.logger.Warn("Failed to export, buffering measurements for retry", zap.String("exporter", e.exp.Name()), zap.Int("count", len(data)), zap.Error(err))
+	}
+	now := time.Now()
+	for _, d := range data {
+		if err := e.buffer(d, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Exporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	if ee, ok := e.exp.(exporter.EventExporter); ok {
+		return ee.ExportEvent(ctx, event)
+	}
+	return nil
+}
+
+func (e *Exporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	if ae, ok := e.exp.(exporter.AggregateExporter); ok {
+		return ae.ExportAggregate(ctx, agg)
+	}
+	return nil
+}
+
+// Close stops replaying and closes the wrapped exporter. Measurements still in the buffer are
+// replayed on the next run.
+func (e *Exporter) Close() error {
+	close(e.quit)
+	<-e.done
+	e.mu.Lock()
+	err := e.store.close()
+	e.mu.Unlock()
+	if cerr := e.exp.Close(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// Stats returns the depth and counters of the buffer
+func (e *Exporter) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.stats
+	s.Records = e.store.pending()
+	s.Bytes = e.store.size()
+	return s
+}
+
+func (e *Exporter) buffer(data sensor.Data, buffered time.Time) error {
+	rec, err := json.Marshal(record{Buffered: buffered, Data: data})
+	if err != nil {
+		return err
+	}
+	rec = append(rec, '\n')
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for e.cfg.MaxSize > 0 && e.store.size()+int64(len(rec)) > e.cfg.MaxSize {
+		n, err := e.store.dropHead()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		e.stats.Dropped += n
+		e.logger.Warn("Retry buffer full, dropped oldest measurements", zap.String("exporter", e.exp.Name()), zap.Int("count", n))
+	}
+	if err := e.store.append(rec); err != nil {
+		return err
+	}
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// next returns the oldest buffered measurement that has not expired
+func (e *Exporter) next() (data sensor.Data, ok bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for {
+		rec, err := e.store.peek()
+		if err != nil || rec == nil {
+			return data, false, err
+		}
+		r, err := decodeRecord(rec)
+		if err != nil {
+			e.logger.Error("Discarding invalid buffered measurement", zap.String("exporter", e.exp.Name()), zap.Error(err))
+		} else if e.cfg.MaxAge > 0 && time.Since(r.Buffered) > e.cfg.MaxAge {
+			e.stats.Expired++
+		} else {
+			return r.Data, true, nil
+		}
+		if err := e.store.ack(); err != nil {
+			return data, false, err
+		}
+	}
+}
+
+// decodeRecord decodes a buffered record. Records buffered by earlier versions contain only
+// the measurement, and their age is based on the measurement timestamp.
+func decodeRecord(b []byte) (r record, err error) {
+	if err = json.Unmarshal(b, &r); err != nil || !r.Buffered.IsZero() {
+		return
+	}
+	err = json.Unmarshal(b, &r.Data)
+	r.Buffered = r.Data.Timestamp
+	return
+}
+
+func (e *Exporter) replay() {
+	defer close(e.done)
+	backoff := e.cfg.MinBackoff
+	for {
+		data, ok, err := e.next()
+		if err != nil {
+			e.logger.Error("Failed to read retry buffer", zap.String("exporter", e.exp.Name()), zap.Error(err))
+		}
+		if !ok {
+			var retry <-chan time.Time
+			if err != nil {
+				retry = time.After(backoff)
+			}
+			select {
+			case <-e.wake:
+			case <-retry:
+			case <-e.quit:
+				return
+			}
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), e.cfg.Timeout)
+		err = e.exp.Export(ctx, data)
+		cancel()
+		if err != nil {
+			e.logger.Debug("Failed to replay measurement", zap.String("exporter", e.exp.Name()), zap.Duration("backoff", backoff), zap.Error(err))
+			select {
+			case <-time.After(backoff):
+			case <-e.quit:
+				return
+			}
+			backoff *= 2
+			if backoff > e.cfg.MaxBackoff {
+				backoff = e.cfg.MaxBackoff
+			}
+			continue
+		}
+		backoff = e.cfg.MinBackoff
+		e.mu.Lock()
+		e.stats.Replayed++
+		err = e.store.ack()
+		e.mu.Unlock()
+		if err != nil {
+			e.logger.Error("Failed to update retry buffer", zap.String("exporter", e.exp.Name()), zap.Error(err))
+		}
+	}
+}
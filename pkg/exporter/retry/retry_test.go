@@ -0,0 +1,208 @@
+package retry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/deadband"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockExporter struct {
+	mu     sync.Mutex
+	down   bool
+	reject string
+	data   []sensor.Data
+}
+
+func (m *mockExporter) Name() string {
+	return "Mock"
+}
+
+func (m *mockExporter) Export(ctx context.Context, data sensor.Data) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return errors.New("connection refused")
+	}
+	if data.Name == m.reject {
+		return errors.New("throttled")
+	}
+	m.data = append(m.data, data)
+	return nil
+}
+
+func (m *mockExporter) Close() error {
+	return nil
+}
+
+func (m *mockExporter) setDown(down bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.down = down
+}
+
+func (m *mockExporter) setReject(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reject = name
+}
+
+func (m *mockExporter) names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var names []string
+	for _, d := range m.data {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+func export(t *testing.T, exp *Exporter, names ...string) {
+	for _, name := range names {
+		require.NoError(t, exp.Export(context.Background(), sensor.Data{Name: name, Timestamp: time.Now()}))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	mock := new(mockExporter)
+	exp, err := New(mock, Config{Dir: tempDir(t), MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+	export(t, exp, "a")
+	mock.setDown(true)
+	export(t, exp, "b", "c")
+	assert.Equal(t, 2, exp.Stats().Records)
+	mock.setDown(false)
+	export(t, exp, "d")
+	require.Eventually(t, func() bool {
+		return exp.Stats().Records == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, mock.names())
+	assert.Equal(t, 3, exp.Stats().Replayed)
+	require.NoError(t, exp.Close())
+}
+
+func TestReplayBehindDeadband(t *testing.T) {
+	mock := new(mockExporter)
+	mock.setDown(true)
+	exp, err := New(mock, Config{Dir: tempDir(t), MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+	db, err := deadband.New(exp, map[string]float64{"temperature": 0.1}, 0)
+	require.NoError(t, err)
+	for i, temp := range []float64{21.5, 21.5, 21.7} {
+		require.NoError(t, db.Export(context.Background(), sensor.Data{
+			Name:        string(rune('a' + i)),
+			Temperature: sensor.Float64(temp),
+			Timestamp:   time.Now(),
+		}))
+	}
+	mock.setDown(false)
+	require.Eventually(t, func() bool {
+		return exp.Stats().Records == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "c"}, mock.names())
+	require.NoError(t, db.Close())
+}
+
+func TestReplayAfterRestart(t *testing.T) {
+	dir := tempDir(t)
+	mock := new(mockExporter)
+	mock.setDown(true)
+	exp, err := New(mock, Config{Dir: dir, MinBackoff: time.Hour}, zap.NewNop())
+	require.NoError(t, err)
+	export(t, exp, "a", "b")
+	require.NoError(t, exp.Close())
+
+	mock.setDown(false)
+	exp, err = New(mock, Config{Dir: dir, MinBackoff: time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return exp.Stats().Records == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, mock.names())
+	require.NoError(t, exp.Close())
+}
+
+func TestMaxAge(t *testing.T) {
+	dir := tempDir(t)
+	mock := new(mockExporter)
+	mock.setDown(true)
+	exp, err := New(mock, Config{Dir: dir, MinBackoff: time.Hour}, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, exp.buffer(sensor.Data{Name: "old", Timestamp: time.Now()}, time.Now().Add(-2*time.Hour)))
+	// The age is based on the buffering time rather than the measurement timestamp
+	require.NoError(t, exp.Export(context.Background(), sensor.Data{Name: "replayed", Timestamp: time.Now().Add(-2 * time.Hour)}))
+	// Records buffered by earlier versions contain only the measurement
+	legacy, err := json.Marshal(sensor.Data{Name: "legacy", Timestamp: time.Now().Add(-2 * time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, exp.store.append(append(legacy, '\n')))
+	export(t, exp, "new")
+	require.NoError(t, exp.Close())
+
+	mock.setDown(false)
+	exp, err = New(mock, Config{Dir: dir, MaxAge: time.Hour, MinBackoff: time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return exp.Stats().Records == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"replayed", "new"}, mock.names())
+	assert.Equal(t, 2, exp.Stats().Expired)
+	require.NoError(t, exp.Close())
+}
+
+func TestMaxSize(t *testing.T) {
+	mock := new(mockExporter)
+	mock.setDown(true)
+	exp, err := New(mock, Config{Dir: tempDir(t), MaxSize: 1000, MinBackoff: time.Hour}, zap.NewNop())
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		export(t, exp, "a")
+	}
+	stats := exp.Stats()
+	assert.True(t, stats.Bytes <= 1000)
+	assert.True(t, stats.Dropped > 0)
+	assert.Equal(t, 100, stats.Records+stats.Dropped)
+	require.NoError(t, exp.Close())
+}
+
+func TestExportBatch(t *testing.T) {
+	mock := new(mockExporter)
+	mock.setDown(true)
+	exp, err := New(mock, Config{Dir: tempDir(t), MinBackoff: time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, exp.ExportBatch(context.Background(), []sensor.Data{
+		{Name: "a", Timestamp: time.Now()},
+		{Name: "b", Timestamp: time.Now()},
+	}))
+	assert.Equal(t, 2, exp.Stats().Records)
+	mock.setDown(false)
+	require.Eventually(t, func() bool {
+		return exp.Stats().Records == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, mock.names())
+	require.NoError(t, exp.Close())
+}
+
+func TestExportPartialBatch(t *testing.T) {
+	mock := new(mockExporter)
+	mock.setReject("b")
+	exp, err := New(mock, Config{Dir: tempDir(t), MinBackoff: time.Hour}, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, exp.ExportBatch(context.Background(), []sensor.Data{
+		{Name: "a", Timestamp: time.Now()},
+		{Name: "b", Timestamp: time.Now()},
+		{Name: "c", Timestamp: time.Now()},
+	}))
+	// Only the measurements that were not exported are buffered
+	assert.Equal(t, []string{"a"}, mock.names())
+	assert.Equal(t, 2, exp.Stats().Records)
+	require.NoError(t, exp.Close())
+}
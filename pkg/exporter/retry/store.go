@@ -0,0 +1,283 @@
+package retry
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	segmentExt = ".seg"
+	cursorFile = "cursor"
+)
+
+type segment struct {
+	id uint64
+	// size is the number of bytes written to the segment
+	size int64
+	// records is the number of unread records in the segment
+	records int
+}
+
+// store is an append-only buffer of newline separated records split into segment files of
+// about segmentSize bytes. The read position is saved in a cursor file so that records are
+// not read again after a restart. Fully read segments are deleted.
+type store struct {
+	dir         string
+	segmentSize int64
+	segments    []segment
+	// offset is the read position in the first segment
+	offset int64
+	w      *os.File
+	r      *os.File
+	br     *bufio.Reader
+	head   []byte
+}
+
+func openStore(dir string, segmentSize int64) (*store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &store{dir: dir, segmentSize: segmentSize}
+	for _, fi := range files {
+		if filepath.Ext(fi.Name()) != segmentExt {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(fi.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg, err := s.load(id)
+		if err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, seg)
+	}
+	sort.Slice(s.segments, func(i, j int) bool {
+		return s.segments[i].id < s.segments[j].id
+	})
+	if err := s.loadCursor(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load reads the size and record count of a segment. A partially written last record is truncated.
+func (s *store) load(id uint64) (segment, error) {
+	path := s.path(id)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return segment{}, err
+	}
+	size := int64(bytes.LastIndexByte(b, '\n') + 1)
+	if size < int64(len(b)) {
+		if err := os.Truncate(path, size); err != nil {
+			return segment{}, err
+		}
+	}
+	return segment{id: id, size: size, records: bytes.Count(b[:size], []byte{'\n'})}, nil
+}
+
+func (s *store) loadCursor() error {
+	b, err := ioutil.ReadFile(filepath.Join(s.dir, cursorFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var id uint64
+	var offset int64
+	if _, err := fmt.Sscan(string(b), &id, &offset); err != nil {
+		return fmt.Errorf("invalid cursor: %w", err)
+	}
+	for len(s.segments) > 0 && s.segments[0].id < id {
+		if err := s.removeHead(); err != nil {
+			return err
+		}
+	}
+	if len(s.segments) == 0 || s.segments[0].id != id {
+		return nil
+	}
+	b, err = ioutil.ReadFile(s.path(id))
+	if err != nil {
+		return err
+	}
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	s.offset = offset
+	s.segments[0].records -= bytes.Count(b[:offset], []byte{'\n'})
+	return nil
+}
+
+// pending returns the number of unread records
+func (s *store) pending() int {
+	n := 0
+	for _, seg := range s.segments {
+		n += seg.records
+	}
+	return n
+}
+
+// size returns the number of bytes in segments, excluding the bytes already read from the first segment
+func (s *store) size() int64 {
+	var n int64
+	for _, seg := range s.segments {
+		n += seg.size
+	}
+	return n - s.offset
+}
+
+// append writes a record that must end with a newline
+func (s *store) append(rec []byte) error {
+	if s.w == nil || s.segments[len(s.segments)-1].size >= s.segmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.w.Write(rec)
+	last := &s.segments[len(s.segments)-1]
+	last.size += int64(n)
+	if err != nil {
+		return err
+	}
+	last.records++
+	return nil
+}
+
+func (s *store) rotate() error {
+	var id uint64 = 1
+	if len(s.segments) > 0 {
+		id = s.segments[len(s.segments)-1].id + 1
+	}
+	f, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if s.w != nil {
+		s.w.Close()
+	}
+	s.w = f
+	s.segments = append(s.segments, segment{id: id})
+	return nil
+}
+
+// peek returns the first unread record or nil if there are none
+func (s *store) peek() ([]byte, error) {
+	if s.head != nil {
+		return s.head, nil
+	}
+	if s.pending() == 0 {
+		return nil, nil
+	}
+	for s.segments[0].records == 0 {
+		if err := s.removeHead(); err != nil {
+			return nil, err
+		}
+	}
+	if s.br == nil {
+		f, err := os.Open(s.path(s.segments[0].id))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		s.r = f
+		s.br = bufio.NewReader(f)
+	}
+	line, err := s.br.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	s.head = line
+	return line, nil
+}
+
+// ack marks the record returned by peek as read. It does nothing if the record has been
+// dropped since.
+func (s *store) ack() error {
+	if s.head == nil {
+		return nil
+	}
+	s.offset += int64(len(s.head))
+	s.segments[0].records--
+	s.head = nil
+	if s.segments[0].records == 0 && !s.writing(0) {
+		if err := s.removeHead(); err != nil {
+			return err
+		}
+	}
+	return s.saveCursor()
+}
+
+// dropHead deletes the oldest segment and returns the number of unread records in it. The
+// segment being written is never dropped.
+func (s *store) dropHead() (int, error) {
+	if len(s.segments) == 0 || s.writing(0) {
+		return 0, nil
+	}
+	n := s.segments[0].records
+	if err := s.removeHead(); err != nil {
+		return 0, err
+	}
+	return n, s.saveCursor()
+}
+
+func (s *store) writing(i int) bool {
+	return s.w != nil && i == len(s.segments)-1
+}
+
+func (s *store) removeHead() error {
+	if s.r != nil {
+		s.r.Close()
+		s.r = nil
+		s.br = nil
+	}
+	s.head = nil
+	if err := os.Remove(s.path(s.segments[0].id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.segments = s.segments[1:]
+	s.offset = 0
+	return nil
+}
+
+func (s *store) saveCursor() error {
+	var id uint64
+	if len(s.segments) > 0 {
+		id = s.segments[0].id
+	}
+	path := filepath.Join(s.dir, cursorFile)
+	if err := ioutil.WriteFile(path+".tmp", []byte(fmt.Sprintf("%d %d\n", id, s.offset)), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (s *store) close() error {
+	if s.r != nil {
+		s.r.Close()
+	}
+	if s.w != nil {
+		return s.w.Close()
+	}
+	return nil
+}
+
+func (s *store) path(id uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
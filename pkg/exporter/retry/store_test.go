@@ -0,0 +1,84 @@
+package retry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "retry")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return dir
+}
+
+func read(t *testing.T, s *store) string {
+	rec, err := s.peek()
+	require.NoError(t, err)
+	require.NoError(t, s.ack())
+	return string(rec)
+}
+
+func TestStore(t *testing.T) {
+	dir := tempDir(t)
+	s, err := openStore(dir, 8)
+	require.NoError(t, err)
+	for _, rec := range []string{"one\n", "two\n", "three\n", "four\n"} {
+		require.NoError(t, s.append([]byte(rec)))
+	}
+	assert.Equal(t, 4, s.pending())
+	assert.Len(t, s.segments, 2)
+	assert.Equal(t, "one\n", read(t, s))
+	assert.Equal(t, "two\n", read(t, s))
+	assert.Len(t, s.segments, 1)
+	assert.Equal(t, int64(11), s.size())
+	require.NoError(t, s.close())
+
+	s, err = openStore(dir, 8)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.pending())
+	assert.Equal(t, "three\n", read(t, s))
+	require.NoError(t, s.append([]byte("five\n")))
+	assert.Equal(t, "four\n", read(t, s))
+	assert.Equal(t, "five\n", read(t, s))
+	rec, err := s.peek()
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+	require.NoError(t, s.close())
+}
+
+func TestStoreTruncatesPartialRecord(t *testing.T) {
+	dir := tempDir(t)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "00000000000000000001.seg"), []byte("one\ntw"), 0644))
+	s, err := openStore(dir, 8)
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.pending())
+	assert.Equal(t, "one\n", read(t, s))
+	assert.Equal(t, 0, s.pending())
+}
+
+func TestStoreDropHead(t *testing.T) {
+	dir := tempDir(t)
+	s, err := openStore(dir, 8)
+	require.NoError(t, err)
+	for _, rec := range []string{"one\n", "two\n", "three\n"} {
+		require.NoError(t, s.append([]byte(rec)))
+	}
+	_, err = s.peek()
+	require.NoError(t, err)
+	n, err := s.dropHead()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	require.NoError(t, s.ack())
+	assert.Equal(t, "three\n", read(t, s))
+	n, err = s.dropHead()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
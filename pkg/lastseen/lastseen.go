@@ -0,0 +1,151 @@
+package lastseen
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Status is the last seen state of a sensor
+type Status struct {
+	Addr     string    `json:"mac"`
+	Name     string    `json:"name"`
+	LastSeen time.Time `json:"last_seen"`
+	RSSI     int       `json:"rssi"`
+	Online   bool      `json:"online"`
+}
+
+// Registry tracks when each sensor was last seen and flags sensors stale when no measurements
+// have been received from them within Timeout. Sensors going offline are detected by calling
+// Check periodically and sensors coming back online are detected from their measurements.
+// Sensors are tracked by the arrival time of their measurements rather than their timestamps
+// so that replayed measurements do not make sensors appear offline.
+type Registry struct {
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	started time.Time
+	tags    map[string]*tag
+}
+
+type tag struct {
+	name     string
+	lastSeen time.Time
+	rssi     int
+	offline  bool
+}
+
+// NewRegistry creates a registry with the given stale timeout. The given peripherals are
+// tracked from the start so that sensors never seen are also flagged offline.
+func NewRegistry(timeout time.Duration, peripherals map[string]string) *Registry {
+	r := &Registry{
+		Timeout: timeout,
+		started: time.Now(),
+		tags:    make(map[string]*tag),
+	}
+	for addr, name := range peripherals {
+		r.tags[addr] = &tag{name: name}
+	}
+	return r
+}
+
+// Detect records the arrival of the measurement and emits an online event if the sensor was offline
+func (r *Registry) Detect(sd sensor.Data) []sensor.Event {
+	return r.Seen(sd, time.Now())
+}
+
+// Seen records that the measurement arrived at the given time and emits an online event if
+// the sensor was offline
+func (r *Registry) Seen(sd sensor.Data, ts time.Time) []sensor.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tags[sd.Addr]
+	if !ok {
+		t = new(tag)
+		r.tags[sd.Addr] = t
+	}
+	if sd.Name != "" {
+		t.name = sd.Name
+	}
+	var events []sensor.Event
+	if t.offline {
+		fields := make(map[string]interface{})
+		if !t.lastSeen.IsZero() {
+			fields["offline_seconds"] = ts.Sub(t.lastSeen).Seconds()
+		}
+		events = append(events, sensor.Event{
+			Type:      sensor.EventOnline,
+			Addr:      sd.Addr,
+			Name:      t.name,
+			Timestamp: ts,
+			Fields:    fields,
+		})
+		t.offline = false
+	}
+	t.lastSeen = ts
+	t.rssi = sd.RSSI
+	return events
+}
+
+// Check flags sensors not seen within the timeout as offline and returns an offline event
+// for each sensor that went offline since the previous check
+func (r *Registry) Check(now time.Time) []sensor.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []sensor.Event
+	for _, addr := range r.addrs() {
+		t := r.tags[addr]
+		if t.offline {
+			continue
+		}
+		since := t.lastSeen
+		if since.IsZero() {
+			since = r.started
+		}
+		if now.Sub(since) <= r.Timeout {
+			continue
+		}
+		t.offline = true
+		fields := make(map[string]interface{})
+		if !t.lastSeen.IsZero() {
+			fields["last_seen"] = t.lastSeen.Format(time.RFC3339)
+		}
+		events = append(events, sensor.Event{
+			Type:      sensor.EventOffline,
+			Addr:      addr,
+			Name:      t.name,
+			Timestamp: now,
+			Fields:    fields,
+		})
+	}
+	return events
+}
+
+// Status returns the state of all tracked sensors ordered by address
+func (r *Registry) Status() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var statuses []Status
+	for _, addr := range r.addrs() {
+		t := r.tags[addr]
+		statuses = append(statuses, Status{
+			Addr:     addr,
+			Name:     t.name,
+			LastSeen: t.lastSeen,
+			RSSI:     t.rssi,
+			Online:   !t.offline && !t.lastSeen.IsZero(),
+		})
+	}
+	return statuses
+}
+
+func (r *Registry) addrs() []string {
+	var addrs []string
+	for addr := range r.tags {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
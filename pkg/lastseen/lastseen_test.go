@@ -0,0 +1,59 @@
+package lastseen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+const (
+	backyard = "cc:ca:7e:52:cc:34"
+	upstairs = "fb:e1:b7:04:95:ee"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(5*time.Minute, map[string]string{
+		backyard: "Backyard",
+		upstairs: "Upstairs",
+	})
+	now := r.started
+	assert.Empty(t, r.Seen(sensor.Data{Addr: backyard, Name: "Backyard", RSSI: -70}, now))
+	assert.Empty(t, r.Check(now.Add(time.Minute)))
+
+	// Upstairs has not been seen since start
+	events := r.Check(now.Add(6 * time.Minute))
+	require.Len(t, events, 2)
+	assert.Equal(t, sensor.EventOffline, events[0].Type)
+	assert.Equal(t, backyard, events[0].Addr)
+	assert.Equal(t, now.Format(time.RFC3339), events[0].Fields["last_seen"])
+	assert.Equal(t, upstairs, events[1].Addr)
+	assert.Equal(t, "Upstairs", events[1].Name)
+	assert.NotContains(t, events[1].Fields, "last_seen")
+
+	// Offline events are only emitted once
+	assert.Empty(t, r.Check(now.Add(10*time.Minute)))
+
+	events = r.Seen(sensor.Data{Addr: backyard, Name: "Backyard", RSSI: -80}, now.Add(10*time.Minute))
+	require.Len(t, events, 1)
+	assert.Equal(t, sensor.EventOnline, events[0].Type)
+	assert.Equal(t, 600.0, events[0].Fields["offline_seconds"])
+	assert.Empty(t, r.Seen(sensor.Data{Addr: backyard}, now.Add(11*time.Minute)))
+
+	statuses := r.Status()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, Status{Addr: backyard, Name: "Backyard", LastSeen: now.Add(11 * time.Minute), Online: true}, statuses[0])
+	assert.Equal(t, Status{Addr: upstairs, Name: "Upstairs"}, statuses[1])
+}
+
+func TestRegistryReplay(t *testing.T) {
+	r := NewRegistry(5*time.Minute, nil)
+	// Replayed measurements are tracked by their arrival
+	recorded := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	assert.Empty(t, r.Detect(sensor.Data{Addr: backyard, Timestamp: recorded}))
+	assert.Empty(t, r.Check(time.Now()))
+	assert.True(t, r.Status()[0].Online)
+}
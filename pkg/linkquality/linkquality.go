@@ -0,0 +1,208 @@
+package linkquality
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Stats contains the link quality statistics of a sensor over the rolling window
+type Stats struct {
+	Addr       string `json:"mac"`
+	Name       string `json:"name"`
+	Received   int    `json:"received"`
+	Expected   int    `json:"expected"`
+	Lost       int    `json:"lost"`
+	Duplicates int    `json:"duplicates"`
+	Gaps       int    `json:"gaps"`
+	Restarts   int    `json:"restarts"`
+	// PacketLoss is the fraction of measurements not received
+	PacketLoss float64 `json:"packet_loss"`
+	// ReceptionRate is the number of measurements received per minute
+	ReceptionRate float64 `json:"reception_rate"`
+	// RSSI is the mean signal strength of the received measurements
+	RSSI float64 `json:"rssi"`
+}
+
+// Fields returns the statistics as event fields
+func (s Stats) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"received":       s.Received,
+		"expected":       s.Expected,
+		"lost":           s.Lost,
+		"duplicates":     s.Duplicates,
+		"gaps":           s.Gaps,
+		"restarts":       s.Restarts,
+		"packet_loss":    s.PacketLoss,
+		"reception_rate": s.ReceptionRate,
+		"rssi":           s.RSSI,
+	}
+}
+
+// Tracker tracks the measurement sequence numbers of sensors. It detects gaps and duplicates
+// and emits a link quality event for each sensor every Interval. The tracker must process
+// every received measurement, before any processor can reject it, so that measurements
+// filtered out later are not counted as lost.
+type Tracker struct {
+	Window   time.Duration
+	Interval time.Duration
+
+	mu   sync.Mutex
+	tags map[string]*tag
+}
+
+type tag struct {
+	name       string
+	seq        int
+	firstSeen  time.Time
+	lastReport time.Time
+	samples    []sample
+}
+
+type sample struct {
+	ts        time.Time
+	expected  int
+	duplicate bool
+	gap       bool
+	restart   bool
+	rssi      int
+}
+
+// NewTracker creates a tracker with the given statistics window and reporting interval
+func NewTracker(window, interval time.Duration) *Tracker {
+	return &Tracker{
+		Window:   window,
+		Interval: interval,
+		tags:     make(map[string]*tag),
+	}
+}
+
+// Process records the sequence number of the measurement and passes it on unchanged
+func (t *Tracker) Process(sd sensor.Data) (sensor.Data, error) {
+	if sd.MeasurementNumber == nil || sd.SequenceModulus <= 0 {
+		return sd, nil
+	}
+	seq := *sd.MeasurementNumber
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tg, ok := t.tags[sd.Addr]
+	if !ok {
+		tg = &tag{
+			seq:        seq,
+			firstSeen:  sd.Timestamp,
+			lastReport: sd.Timestamp,
+		}
+		t.tags[sd.Addr] = tg
+		tg.name = sd.Name
+		tg.samples = append(tg.samples, sample{ts: sd.Timestamp, expected: 1, rssi: sd.RSSI})
+		return sd, nil
+	}
+	tg.name = sd.Name
+	s := sample{ts: sd.Timestamp, rssi: sd.RSSI}
+	// Larger jumps, including sequence numbers going backwards, are treated as the sensor restarting
+	switch delta := Delta(tg.seq, seq, sd.SequenceModulus); {
+	case delta == 0:
+		s.duplicate = true
+	case delta > sd.SequenceModulus/2:
+		s.restart = true
+		s.expected = 1
+	default:
+		s.gap = delta > 1
+		s.expected = delta
+	}
+	tg.seq = seq
+	tg.samples = append(tg.samples, s)
+	tg.prune(sd.Timestamp.Add(-t.Window))
+	return sd, nil
+}
+
+// Detect returns a link quality event if the reporting interval of the sensor has elapsed
+func (t *Tracker) Detect(sd sensor.Data) []sensor.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tg, ok := t.tags[sd.Addr]
+	if !ok || sd.Timestamp.Sub(tg.lastReport) < t.Interval {
+		return nil
+	}
+	tg.lastReport = sd.Timestamp
+	stats := tg.stats(sd.Addr, sd.Timestamp, t.Window)
+	return []sensor.Event{
+		{
+			Type:      sensor.EventLinkQuality,
+			Addr:      sd.Addr,
+			Name:      sd.Name,
+			Timestamp: sd.Timestamp,
+			Fields:    stats.Fields(),
+		},
+	}
+}
+
+// Stats returns the current statistics of all tracked sensors ordered by address
+func (t *Tracker) Stats() []Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var stats []Stats
+	for addr, tg := range t.tags {
+		if len(tg.samples) == 0 {
+			continue
+		}
+		stats = append(stats, tg.stats(addr, tg.samples[len(tg.samples)-1].ts, t.Window))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Addr < stats[j].Addr
+	})
+	return stats
+}
+
+func (tg *tag) prune(oldest time.Time) {
+	i := 0
+	for i < len(tg.samples) && tg.samples[i].ts.Before(oldest) {
+		i++
+	}
+	tg.samples = tg.samples[i:]
+}
+
+func (tg *tag) stats(addr string, now time.Time, window time.Duration) Stats {
+	s := Stats{
+		Addr: addr,
+		Name: tg.name,
+	}
+	rssi := 0
+	for _, smp := range tg.samples {
+		switch {
+		case smp.duplicate:
+			s.Duplicates++
+			continue
+		case smp.restart:
+			s.Restarts++
+		case smp.gap:
+			s.Gaps++
+		}
+		s.Received++
+		s.Expected += smp.expected
+		rssi += smp.rssi
+	}
+	s.Lost = s.Expected - s.Received
+	if s.Expected > 0 {
+		s.PacketLoss = float64(s.Lost) / float64(s.Expected)
+	}
+	if s.Received > 0 {
+		s.RSSI = float64(rssi) / float64(s.Received)
+	}
+	span := now.Sub(tg.firstSeen)
+	if span > window {
+		span = window
+	}
+	if span > 0 {
+		s.ReceptionRate = float64(s.Received) / span.Minutes()
+	}
+	return s
+}
+
+// Delta returns the increase between two sequence numbers taking wraparound at the modulus
+// into account
+func Delta(prev, cur, modulus int) int {
+	return ((cur-prev)%modulus + modulus) % modulus
+}
@@ -0,0 +1,108 @@
+package linkquality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestDelta(t *testing.T) {
+	assert.Equal(t, 0, Delta(100, 100, 65535))
+	assert.Equal(t, 1, Delta(100, 101, 65535))
+	assert.Equal(t, 1, Delta(65534, 0, 65535))
+	assert.Equal(t, 3, Delta(65533, 1, 65535))
+	assert.Equal(t, 1, Delta(255, 0, 256))
+	assert.Equal(t, 2, Delta(0xFFFFFE, 1, 0xFFFFFF))
+}
+
+func TestTracker(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(10*time.Minute, time.Minute)
+	assert.Empty(t, observe(tr, data(65532, ts, -70)))
+	assert.Empty(t, observe(tr, data(65533, ts.Add(10*time.Second), -80)))
+	// Duplicate
+	assert.Empty(t, observe(tr, data(65533, ts.Add(11*time.Second), -80)))
+	// Wraparound with two lost measurements
+	assert.Empty(t, observe(tr, data(1, ts.Add(30*time.Second), -60)))
+	events := observe(tr, data(2, ts.Add(time.Minute), -70))
+	require.Len(t, events, 1)
+	assert.Equal(t, sensor.EventLinkQuality, events[0].Type)
+	assert.Equal(t, "cc:ca:7e:52:cc:34", events[0].Addr)
+	stats := tr.Stats()
+	require.Len(t, stats, 1)
+	s := stats[0]
+	assert.Equal(t, 4, s.Received)
+	assert.Equal(t, 6, s.Expected)
+	assert.Equal(t, 2, s.Lost)
+	assert.Equal(t, 1, s.Duplicates)
+	assert.Equal(t, 1, s.Gaps)
+	assert.Equal(t, 0, s.Restarts)
+	assert.InDelta(t, 0.333, s.PacketLoss, 0.001)
+	assert.InDelta(t, 4.0, s.ReceptionRate, 0.001)
+	assert.InDelta(t, -70.0, s.RSSI, 0.001)
+	assert.Equal(t, s.Fields(), events[0].Fields)
+
+	// Restarted sensor
+	assert.Empty(t, observe(tr, data(0, ts.Add(70*time.Second), -70)))
+	assert.Equal(t, 1, tr.Stats()[0].Restarts)
+	assert.Equal(t, 2, tr.Stats()[0].Lost)
+
+	// Old samples fall out of the window
+	observe(tr, data(1, ts.Add(20*time.Minute), -70))
+	s = tr.Stats()[0]
+	assert.Equal(t, 1, s.Received)
+	assert.Equal(t, 0, s.Lost)
+}
+
+func TestTrackerProcessedOnly(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(10*time.Minute, time.Minute)
+	assert.Empty(t, observe(tr, data(10, ts, -70)))
+	// Measurements that are processed but not exported are not lost
+	for i := 1; i < 5; i++ {
+		_, err := tr.Process(data(10+i, ts.Add(time.Duration(i)*10*time.Second), -70))
+		require.NoError(t, err)
+	}
+	events := observe(tr, data(15, ts.Add(time.Minute), -70))
+	require.Len(t, events, 1)
+	assert.Equal(t, 6, events[0].Fields["received"])
+	assert.Equal(t, 0, events[0].Fields["lost"])
+}
+
+func TestTracker8BitSequence(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	tr := NewTracker(10*time.Minute, time.Minute)
+	sd := data(254, ts, -70)
+	sd.SequenceModulus = 256
+	observe(tr, sd)
+	sd = data(1, ts.Add(10*time.Second), -70)
+	sd.SequenceModulus = 256
+	observe(tr, sd)
+	s := tr.Stats()[0]
+	assert.Equal(t, 0, s.Restarts)
+	assert.Equal(t, 1, s.Gaps)
+	assert.Equal(t, 2, s.Lost)
+}
+
+func observe(tr *Tracker, sd sensor.Data) []sensor.Event {
+	sd, err := tr.Process(sd)
+	if err != nil {
+		panic(err)
+	}
+	return tr.Detect(sd)
+}
+
+func data(seq int, ts time.Time, rssi int) sensor.Data {
+	return sensor.Data{
+		Addr:              "cc:ca:7e:52:cc:34",
+		Name:              "Backyard",
+		MeasurementNumber: sensor.Int(seq),
+		SequenceModulus:   65535,
+		RSSI:              rssi,
+		Timestamp:         ts,
+	}
+}
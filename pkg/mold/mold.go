@@ -0,0 +1,198 @@
+package mold
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Wood species of the VTT model
+const (
+	Pine   = 0
+	Spruce = 1
+)
+
+// Surface qualities of the VTT model
+const (
+	Sawn      = 0
+	KilnDried = 1
+)
+
+// MaxStep is the longest time between measurements integrated into the index. Longer gaps
+// are integrated as MaxStep to avoid extrapolating old conditions.
+const MaxStep = time.Hour
+
+// Model is the VTT mold growth model (Hukka & Viitanen 1999) for a wooden material
+type Model struct {
+	Species int
+	Surface int
+}
+
+// CriticalHumidity returns the relative humidity (percent) above which mold can grow at the given temperature (°C)
+func CriticalHumidity(temp float64) float64 {
+	if temp > 20 {
+		return 80
+	}
+	return -0.00267*temp*temp*temp + 0.160*temp*temp - 3.13*temp + 100.0
+}
+
+// Favourable returns true if mold can grow in the given conditions
+func Favourable(temp, humidity float64) bool {
+	return temp > 0 && temp < 50 && humidity >= CriticalHumidity(temp)
+}
+
+// Growth returns the growth rate of the mold index per day in favourable conditions
+func (m Model) Growth(index, temp, humidity float64) float64 {
+	lnT := math.Log(temp)
+	lnRH := math.Log(humidity)
+	// Weeks to reach index 1 and 3
+	tm := math.Exp(-0.68*lnT - 13.9*lnRH + 0.14*float64(m.Species) - 0.33*float64(m.Surface) + 66.02)
+	tv := math.Exp(-0.74*lnT - 12.72*lnRH + 0.06*float64(m.Species) + 61.50)
+	k1 := 1.0
+	if index >= 1 {
+		k1 = 2 / (tv/tm - 1)
+	}
+	rhCrit := CriticalHumidity(temp)
+	x := (rhCrit - humidity) / (rhCrit - 100)
+	maxIndex := 1 + 7*x - 2*x*x
+	k2 := math.Max(1-math.Exp(2.3*(index-maxIndex)), 0)
+	return k1 * k2 / (7 * tm)
+}
+
+// Decline returns the decline rate of the mold index per hour after the given time in unfavourable conditions
+func Decline(dry time.Duration) float64 {
+	switch {
+	case dry <= 6*time.Hour:
+		return 0.00133
+	case dry <= 24*time.Hour:
+		return 0
+	default:
+		return 0.000667
+	}
+}
+
+// State is the accumulated mold index of a sensor
+type State struct {
+	Index    float64   `json:"index"`
+	Updated  time.Time `json:"updated"`
+	DryStart time.Time `json:"dry_start,omitempty"`
+}
+
+// Update integrates the conditions over the time since the previous update. Samples that are
+// not newer than the previous update are ignored.
+func (m Model) Update(s State, temp, humidity float64, ts time.Time) State {
+	if s.Updated.IsZero() {
+		s.Updated = ts
+		return s
+	}
+	if !ts.After(s.Updated) {
+		return s
+	}
+	step := ts.Sub(s.Updated)
+	if step > MaxStep {
+		step = MaxStep
+	}
+	if Favourable(temp, humidity) {
+		s.DryStart = time.Time{}
+		s.Index += m.Growth(s.Index, temp, humidity) * step.Hours() / 24
+	} else {
+		if s.DryStart.IsZero() {
+			s.DryStart = s.Updated
+		}
+		s.Index -= Decline(ts.Sub(s.DryStart)) * step.Hours()
+	}
+	s.Index = math.Max(0, math.Min(6, s.Index))
+	s.Updated = ts
+	return s
+}
+
+// Accumulator accumulates the mold index of each sensor from its temperature and humidity history.
+// If StateFile is set, the accumulated indexes are saved to it every SaveInterval and loaded on startup.
+type Accumulator struct {
+	Model        Model
+	StateFile    string
+	SaveInterval time.Duration
+	Logger       *zap.Logger
+
+	mu     sync.Mutex
+	states map[string]State
+	saved  time.Time
+}
+
+// NewAccumulator creates an accumulator, loading previously saved states from stateFile if it exists
+func NewAccumulator(model Model, stateFile string, saveInterval time.Duration) (*Accumulator, error) {
+	a := &Accumulator{
+		Model:        model,
+		StateFile:    stateFile,
+		SaveInterval: saveInterval,
+		states:       make(map[string]State),
+		Logger:       zap.NewNop(),
+	}
+	if stateFile == "" {
+		return a, nil
+	}
+	data, err := ioutil.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &a.states); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Process updates the mold index of the sensor and adds it to the measurement
+func (a *Accumulator) Process(sd sensor.Data) (sensor.Data, error) {
+	if sd.Temperature == nil || sd.Humidity == nil {
+		return sd, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.Model.Update(a.states[sd.Addr], *sd.Temperature, *sd.Humidity, sd.Timestamp)
+	a.states[sd.Addr] = s
+	sd.MoldIndex = sensor.Float64(s.Index)
+	if a.StateFile != "" && sd.Timestamp.Sub(a.saved) >= a.SaveInterval {
+		a.saved = sd.Timestamp
+		if err := a.save(); err != nil {
+			a.Logger.Error("Failed to save mold index state", zap.String("file", a.StateFile), zap.Error(err))
+		}
+	}
+	return sd, nil
+}
+
+// Save writes the mold index states to the state file
+func (a *Accumulator) Save() error {
+	if a.StateFile == "" {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.save()
+}
+
+// Close saves the mold index states so that no growth is lost between periodic saves
+func (a *Accumulator) Close() error {
+	return a.Save()
+}
+
+func (a *Accumulator) save() error {
+	data, err := json.Marshal(a.states)
+	if err != nil {
+		return err
+	}
+	tmp := a.StateFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, a.StateFile)
+}
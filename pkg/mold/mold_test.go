@@ -0,0 +1,113 @@
+package mold
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestCriticalHumidity(t *testing.T) {
+	assert.Equal(t, 80.0, CriticalHumidity(25))
+	assert.InDelta(t, 80.0, CriticalHumidity(20), 0.1)
+	assert.InDelta(t, 97.0, CriticalHumidity(1), 0.1)
+	assert.True(t, Favourable(20, 90))
+	assert.False(t, Favourable(20, 70))
+	assert.False(t, Favourable(-5, 100))
+}
+
+func TestUpdate(t *testing.T) {
+	m := Model{Species: Pine, Surface: Sawn}
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	var s State
+	s = m.Update(s, 20, 97, ts)
+	assert.Equal(t, 0.0, s.Index)
+	// Mold starts to grow in about 10 days in humid conditions
+	for h := 1; h <= 24*14; h++ {
+		s = m.Update(s, 20, 97, ts.Add(time.Duration(h)*time.Hour))
+	}
+	assert.Greater(t, s.Index, 1.0)
+	assert.Less(t, s.Index, 2.0)
+	grown := s.Index
+	// Dry conditions make the index decline
+	for h := 1; h <= 24*7; h++ {
+		s = m.Update(s, 20, 50, ts.Add(time.Duration(24*14+h)*time.Hour))
+	}
+	assert.Less(t, s.Index, grown)
+	assert.GreaterOrEqual(t, s.Index, 0.0)
+	// Long gaps are limited to MaxStep
+	before := s
+	s = m.Update(s, 20, 97, s.Updated.Add(30*24*time.Hour))
+	assert.InDelta(t, before.Index, s.Index, 0.01)
+	// Out-of-order samples are ignored
+	before = s
+	assert.Equal(t, before, m.Update(s, 20, 97, s.Updated.Add(-time.Hour)))
+}
+
+func TestAccumulator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "mold.json")
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	a, err := NewAccumulator(Model{}, stateFile, time.Hour)
+	require.NoError(t, err)
+	var sd sensor.Data
+	for h := 0; h <= 24*14; h++ {
+		sd, err = a.Process(sensor.Data{
+			Addr:        "cc:ca:7e:52:cc:34",
+			Temperature: sensor.Float64(20),
+			Humidity:    sensor.Float64(97),
+			Timestamp:   ts.Add(time.Duration(h) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	require.NotNil(t, sd.MoldIndex)
+	assert.Greater(t, *sd.MoldIndex, 1.0)
+
+	// State is restored from the state file
+	a, err = NewAccumulator(Model{}, stateFile, time.Hour)
+	require.NoError(t, err)
+	sd, err = a.Process(sensor.Data{
+		Addr:        "cc:ca:7e:52:cc:34",
+		Temperature: sensor.Float64(20),
+		Humidity:    sensor.Float64(97),
+		Timestamp:   ts.Add(24*14*time.Hour + time.Minute),
+	})
+	require.NoError(t, err)
+	assert.Greater(t, *sd.MoldIndex, 1.0)
+
+	sd, err = a.Process(sensor.Data{Addr: "cc:ca:7e:52:cc:34", Temperature: sensor.Float64(20)})
+	require.NoError(t, err)
+	assert.Nil(t, sd.MoldIndex)
+}
+
+func TestAccumulatorClose(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "mold.json")
+	a, err := NewAccumulator(Model{}, stateFile, time.Hour)
+	require.NoError(t, err)
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	for m := 0; m < 30; m++ {
+		_, err = a.Process(sensor.Data{
+			Addr:        "cc:ca:7e:52:cc:34",
+			Temperature: sensor.Float64(20),
+			Humidity:    sensor.Float64(97),
+			Timestamp:   ts.Add(time.Duration(m) * time.Minute),
+		})
+		require.NoError(t, err)
+	}
+	// Measurements since the last periodic save are saved on close
+	require.NoError(t, a.Close())
+	restored, err := NewAccumulator(Model{}, stateFile, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, a.states, restored.states)
+}
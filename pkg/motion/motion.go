@@ -0,0 +1,54 @@
+package motion
+
+import (
+	"sync"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Detector tracks the movement counters of sensors and emits a motion event whenever a counter increases
+type Detector struct {
+	mu       sync.Mutex
+	counters map[string]int
+}
+
+// Detect returns a motion event if the movement counter has increased since the previous measurement
+// of the same sensor. The first measurement of each sensor only initializes the tracked counter.
+// Measurements of data formats without a movement counter are ignored.
+func (d *Detector) Detect(sd sensor.Data) []sensor.Event {
+	if sd.MovementCounter < 0 || sd.MovementCounter >= sd.MovementModulus {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.counters == nil {
+		d.counters = make(map[string]int)
+	}
+	prev, ok := d.counters[sd.Addr]
+	d.counters[sd.Addr] = sd.MovementCounter
+	if !ok {
+		return nil
+	}
+	delta := Delta(prev, sd.MovementCounter, sd.MovementModulus)
+	if delta == 0 {
+		return nil
+	}
+	return []sensor.Event{
+		{
+			Type:      sensor.EventMotion,
+			Addr:      sd.Addr,
+			Name:      sd.Name,
+			Timestamp: sd.Timestamp,
+			Fields: map[string]interface{}{
+				"delta":            delta,
+				"movement_counter": sd.MovementCounter,
+			},
+		},
+	}
+}
+
+// Delta returns the number of movements between two counter values taking wraparound at the
+// modulus into account
+func Delta(prev, cur, modulus int) int {
+	return ((cur-prev)%modulus + modulus) % modulus
+}
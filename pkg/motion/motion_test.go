@@ -0,0 +1,81 @@
+package motion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestDelta(t *testing.T) {
+	assert.Equal(t, 0, Delta(10, 10, 255))
+	assert.Equal(t, 3, Delta(10, 13, 255))
+	assert.Equal(t, 1, Delta(254, 0, 255))
+	assert.Equal(t, 5, Delta(252, 2, 255))
+	assert.Equal(t, 3, Delta(65533, 1, 65535))
+}
+
+func TestDetector(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	d := new(Detector)
+	assert.Empty(t, d.Detect(data(252, ts)))
+	assert.Empty(t, d.Detect(data(252, ts.Add(time.Second))))
+	events := d.Detect(data(1, ts.Add(2*time.Second)))
+	require.Len(t, events, 1)
+	assert.Equal(t, sensor.Event{
+		Type:      sensor.EventMotion,
+		Addr:      "cc:ca:7e:52:cc:34",
+		Name:      "Mailbox",
+		Timestamp: ts.Add(2 * time.Second),
+		Fields: map[string]interface{}{
+			"delta":            4,
+			"movement_counter": 1,
+		},
+	}, events[0])
+	// Invalid counter values are ignored
+	assert.Empty(t, d.Detect(data(255, ts.Add(3*time.Second))))
+	assert.Len(t, d.Detect(data(2, ts.Add(4*time.Second))), 1)
+}
+
+func TestDetectorFormat8(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	d := new(Detector)
+	assert.Empty(t, d.Detect(data8(300, ts)))
+	events := d.Detect(data8(302, ts.Add(time.Second)))
+	require.Len(t, events, 1)
+	assert.Equal(t, 2, events[0].Fields["delta"])
+	assert.Len(t, d.Detect(data8(65534, ts.Add(2*time.Second))), 1)
+	events = d.Detect(data8(1, ts.Add(3*time.Second)))
+	require.Len(t, events, 1)
+	assert.Equal(t, 2, events[0].Fields["delta"])
+	// Invalid counter values are ignored
+	assert.Empty(t, d.Detect(data8(65535, ts.Add(4*time.Second))))
+}
+
+func TestDetectorWithoutCounter(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	d := new(Detector)
+	sd := data(0, ts)
+	sd.MovementModulus = 0
+	assert.Empty(t, d.Detect(sd))
+	assert.Empty(t, d.Detect(sd))
+}
+
+func data(counter int, ts time.Time) sensor.Data {
+	return sensor.Data{
+		Addr:            "cc:ca:7e:52:cc:34",
+		Name:            "Mailbox",
+		MovementCounter: counter,
+		MovementModulus: 255,
+		Timestamp:       ts,
+	}
+}
+
+func data8(counter int, ts time.Time) sensor.Data {
+	sd := data(counter, ts)
+	sd.MovementModulus = 65535
+	return sd
+}
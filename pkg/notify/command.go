@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Command runs an external command for each notification. The notification message is written
+// to the standard input of the command and the notification is passed as JSON in the
+// RUUVITAG_NOTIFICATION environment variable.
+type Command struct {
+	Path string
+	Args []string
+}
+
+func (c Command) Name() string {
+	return fmt.Sprintf("Command (%s)", c.Path)
+}
+
+func (c Command) Notify(ctx context.Context, n Notification) error {
+	j, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Env = append(os.Environ(),
+		"RUUVITAG_SUBJECT="+n.Subject,
+		"RUUVITAG_EVENT="+n.Event.Type,
+		"RUUVITAG_MAC="+n.Event.Addr,
+		"RUUVITAG_NAME="+n.Event.Name,
+		"RUUVITAG_NOTIFICATION="+string(j),
+	)
+	cmd.Stdin = strings.NewReader(n.Message)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("command %s failed: %w: %s", c.Path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (c Command) Close() error {
+	return nil
+}
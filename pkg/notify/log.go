@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Log writes notifications to the log
+type Log struct {
+	Logger *zap.Logger
+}
+
+func (l Log) Name() string {
+	return "Log"
+}
+
+func (l Log) Notify(ctx context.Context, n Notification) error {
+	l.Logger.Warn(n.Subject, zap.String("mac", n.Event.Addr), zap.Any("fields", n.Event.Fields))
+	return nil
+}
+
+func (l Log) Close() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Notification is a message sent to users about an event
+type Notification struct {
+	Subject string       `json:"subject"`
+	Message string       `json:"message"`
+	Event   sensor.Event `json:"event"`
+}
+
+// Notifier sends notifications
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, n Notification) error
+	Close() error
+}
+
+// FromEvent creates a notification describing the event
+func FromEvent(event sensor.Event) Notification {
+	name := event.Name
+	if name == "" {
+		name = event.Addr
+	}
+	subject := fmt.Sprintf("%s: %s", name, strings.ReplaceAll(event.Type, "_", " "))
+	var keys []string
+	for k := range event.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s at %s", subject, event.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n%s: %v", k, event.Fields[k])
+	}
+	return Notification{
+		Subject: subject,
+		Message: b.String(),
+		Event:   event,
+	}
+}
+
+// Exporter sends a notification of each exported event of the selected types.
+// Measurements are ignored.
+type Exporter struct {
+	Notifier Notifier
+	// EventTypes contains the event types to send notifications of
+	EventTypes map[string]bool
+}
+
+func (e Exporter) Name() string {
+	return e.Notifier.Name()
+}
+
+func (e Exporter) Export(ctx context.Context, data sensor.Data) error {
+	return nil
+}
+
+func (e Exporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	if !e.EventTypes[event.Type] {
+		return nil
+	}
+	return e.Notifier.Notify(ctx, FromEvent(event))
+}
+
+func (e Exporter) Close() error {
+	return e.Notifier.Close()
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockNotifier struct {
+	notifications []Notification
+}
+
+func (m *mockNotifier) Name() string {
+	return "Mock"
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, n Notification) error {
+	m.notifications = append(m.notifications, n)
+	return nil
+}
+
+func (m *mockNotifier) Close() error {
+	return nil
+}
+
+var testEvent = sensor.Event{
+	Type:      sensor.EventLowBattery,
+	Addr:      "cc:ca:7e:52:cc:34",
+	Name:      "Backyard",
+	Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC),
+	Fields: map[string]interface{}{
+		"battery_voltage": 2.5,
+		"battery_level":   6.0,
+	},
+}
+
+func TestFromEvent(t *testing.T) {
+	n := FromEvent(testEvent)
+	assert.Equal(t, "Backyard: low battery", n.Subject)
+	assert.Equal(t, "Backyard: low battery at 2020-01-01 12:00:00 UTC\nbattery_level: 6\nbattery_voltage: 2.5", n.Message)
+}
+
+func TestExporter(t *testing.T) {
+	n := new(mockNotifier)
+	exp := Exporter{
+		Notifier:   n,
+		EventTypes: map[string]bool{sensor.EventLowBattery: true},
+	}
+	require.NoError(t, exp.ExportEvent(context.Background(), testEvent))
+	require.NoError(t, exp.ExportEvent(context.Background(), sensor.Event{Type: sensor.EventMotion}))
+	require.Len(t, n.notifications, 1)
+	assert.Equal(t, testEvent, n.notifications[0].Event)
+}
+
+func TestWebhook(t *testing.T) {
+	var received Notification
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+	w, err := NewWebhook(srv.URL, "secret", time.Second)
+	require.NoError(t, err)
+	defer w.Close()
+	require.NoError(t, w.Notify(context.Background(), FromEvent(testEvent)))
+	assert.Equal(t, "Backyard: low battery", received.Subject)
+	assert.Equal(t, testEvent.Addr, received.Event.Addr)
+}
+
+func TestCommand(t *testing.T) {
+	c := Command{Path: "sh", Args: []string{"-c", `test "$RUUVITAG_EVENT" = low_battery && grep -q "battery_level: 6"`}}
+	assert.NoError(t, c.Notify(context.Background(), FromEvent(testEvent)))
+	c = Command{Path: "sh", Args: []string{"-c", "echo failed; exit 1"}}
+	assert.EqualError(t, c.Notify(context.Background(), FromEvent(testEvent)), "command sh failed: exit status 1: failed")
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig contains the settings for sending notifications by email
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// SMTP sends notifications by email
+type SMTP struct {
+	cfg SMTPConfig
+}
+
+// NewSMTP creates an SMTP notifier
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host must be specified")
+	}
+	if cfg.From == "" || len(cfg.To) == 0 {
+		return nil, fmt.Errorf("SMTP sender and recipients must be specified")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTP{cfg: cfg}, nil
+}
+
+func (s *SMTP) Name() string {
+	return fmt.Sprintf("SMTP (%s)", s.cfg.Host)
+}
+
+func (s *SMTP) Notify(ctx context.Context, n Notification) error {
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, s.cfg.From, s.cfg.To, s.message(n))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SMTP) message(n Notification) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", n.Subject)
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(n.Message, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+func (s *SMTP) Close() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook posts notifications as JSON to a URL
+type Webhook struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+// NewWebhook creates a webhook notifier. The token is sent as a bearer token if not empty.
+func NewWebhook(url, token string, timeout time.Duration) (*Webhook, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook URL must be specified")
+	}
+	return &Webhook{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+		token:  token,
+	}, nil
+}
+
+func (w *Webhook) Name() string {
+	return fmt.Sprintf("Webhook (%s)", w.url)
+}
+
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("From", "ruuvitag-gollector")
+	if w.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.token))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *Webhook) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,75 @@
+package orientation
+
+import (
+	"math"
+	"sync"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Angles calculates pitch and roll (degrees) and the total acceleration (g) from acceleration in milli-g
+func Angles(x, y, z int) (pitch, roll, g float64) {
+	ax := float64(x) / 1000.0
+	ay := float64(y) / 1000.0
+	az := float64(z) / 1000.0
+	pitch = math.Atan2(-ax, math.Sqrt(ay*ay+az*az)) * 180 / math.Pi
+	roll = math.Atan2(ay, az) * 180 / math.Pi
+	g = math.Sqrt(ax*ax + ay*ay + az*az)
+	return
+}
+
+// Tracker adds pitch, roll and total acceleration to measurements. If Threshold is greater
+// than zero, it also flags measurements where pitch or roll differs from the previous
+// orientation of the sensor by more than Threshold degrees.
+type Tracker struct {
+	Threshold float64
+
+	mu        sync.Mutex
+	reference map[string]orientation
+}
+
+type orientation struct {
+	pitch, roll float64
+}
+
+// Process calculates the orientation of the sensor from the acceleration in the measurement
+func (t *Tracker) Process(sd sensor.Data) (sensor.Data, error) {
+	if sd.AccelerationX == nil || sd.AccelerationY == nil || sd.AccelerationZ == nil {
+		return sd, nil
+	}
+	pitch, roll, g := Angles(*sd.AccelerationX, *sd.AccelerationY, *sd.AccelerationZ)
+	sd.Pitch = sensor.Float64(pitch)
+	sd.Roll = sensor.Float64(roll)
+	sd.GForce = sensor.Float64(g)
+	if t.Threshold > 0 {
+		sd.OrientationChanged = sensor.Bool(t.changed(sd.Addr, orientation{pitch: pitch, roll: roll}))
+	}
+	return sd, nil
+}
+
+func (t *Tracker) changed(addr string, o orientation) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.reference == nil {
+		t.reference = make(map[string]orientation)
+	}
+	ref, ok := t.reference[addr]
+	if !ok {
+		t.reference[addr] = o
+		return false
+	}
+	if angleDiff(o.pitch, ref.pitch) > t.Threshold || angleDiff(o.roll, ref.roll) > t.Threshold {
+		t.reference[addr] = o
+		return true
+	}
+	return false
+}
+
+// angleDiff returns the absolute difference of two angles in degrees taking wraparound into account
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
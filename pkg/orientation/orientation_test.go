@@ -0,0 +1,71 @@
+package orientation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestAngles(t *testing.T) {
+	pitch, roll, g := Angles(0, 0, 1000)
+	assert.InDelta(t, 0, pitch, 0.001)
+	assert.InDelta(t, 0, roll, 0.001)
+	assert.InDelta(t, 1, g, 0.001)
+
+	pitch, roll, _ = Angles(-1000, 0, 0)
+	assert.InDelta(t, 90, pitch, 0.001)
+
+	pitch, roll, _ = Angles(0, 1000, 0)
+	assert.InDelta(t, 0, pitch, 0.001)
+	assert.InDelta(t, 90, roll, 0.001)
+
+	_, _, g = Angles(-20, 40, 2000)
+	assert.InDelta(t, 2.0005, g, 0.0001)
+}
+
+func TestTracker(t *testing.T) {
+	tr := &Tracker{Threshold: 30}
+	sd, err := tr.Process(data(0, 0, 1000))
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Bool(false), sd.OrientationChanged)
+	require.NotNil(t, sd.Pitch)
+	require.NotNil(t, sd.Roll)
+	require.NotNil(t, sd.GForce)
+
+	// Small movements do not change orientation
+	sd, err = tr.Process(data(0, 200, 980))
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Bool(false), sd.OrientationChanged)
+
+	// Door opened 90 degrees
+	sd, err = tr.Process(data(0, 1000, 0))
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Bool(true), sd.OrientationChanged)
+
+	// Compared to the new orientation
+	sd, err = tr.Process(data(0, 1000, 50))
+	require.NoError(t, err)
+	assert.Equal(t, sensor.Bool(false), sd.OrientationChanged)
+
+	sd, err = tr.Process(sensor.Data{Addr: "cc:ca:7e:52:cc:34"})
+	require.NoError(t, err)
+	assert.Nil(t, sd.Pitch)
+	assert.Nil(t, sd.OrientationChanged)
+}
+
+func TestAngleDiff(t *testing.T) {
+	assert.Equal(t, 20.0, angleDiff(170, -170))
+	assert.Equal(t, 20.0, angleDiff(-10, 10))
+}
+
+func data(x, y, z int) sensor.Data {
+	return sensor.Data{
+		Addr:          "cc:ca:7e:52:cc:34",
+		AccelerationX: sensor.Int(x),
+		AccelerationY: sensor.Int(y),
+		AccelerationZ: sensor.Int(z),
+	}
+}
@@ -0,0 +1,177 @@
+package outlier
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/aggregate"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// ErrRejected is returned for measurements rejected as outliers
+var ErrRejected = errors.New("measurement rejected as outlier")
+
+// madScale scales the median absolute deviation to the standard deviation of normally distributed data
+const madScale = 1.4826
+
+// Check contains the outlier checks of a measurement field
+type Check struct {
+	// Min and Max are the physically possible range of the field
+	Min *float64
+	Max *float64
+	// MaxRate is the maximum change of the field per minute from the previous accepted
+	// measurement, 0 to disable. Changes up to MaxRate are allowed regardless of the time
+	// between measurements to tolerate sensor resolution.
+	MaxRate float64
+	// Hampel enables rejecting values deviating from the median of the window by more than
+	// K scaled median absolute deviations
+	Hampel bool
+	// MinDeviation is the smallest deviation from the median rejected by the Hampel filter.
+	// It prevents rejecting small changes when the window contains constant values.
+	MinDeviation float64
+}
+
+// Filter rejects outlier measurements. Each sensor has its own rate of change reference
+// and window of recent values.
+type Filter struct {
+	Checks map[string]Check
+	// Window is the number of previous values of each field used by the Hampel filter
+	Window int
+	// K is the number of scaled median absolute deviations allowed by the Hampel filter
+	K float64
+
+	mu         sync.Mutex
+	tags       map[string]*tag
+	rejections map[string]map[string]int
+}
+
+type tag struct {
+	accepted sensor.Data
+	windows  map[string][]float64
+}
+
+// Process returns an error wrapping ErrRejected if any checked field of the measurement is an outlier
+func (f *Filter) Process(sd sensor.Data) (sensor.Data, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tags == nil {
+		f.tags = make(map[string]*tag)
+		f.rejections = make(map[string]map[string]int)
+	}
+	t, ok := f.tags[sd.Addr]
+	if !ok {
+		t = &tag{windows: make(map[string][]float64)}
+		f.tags[sd.Addr] = t
+	}
+	var err error
+	for _, field := range f.fields() {
+		v, ok := sd.Value(field)
+		if !ok {
+			continue
+		}
+		if err == nil {
+			err = f.check(t, sd, field, v)
+		}
+		if f.Checks[field].Hampel && f.Window > 0 {
+			// Rejected values are kept in the window so that a lasting step change is
+			// accepted once it dominates the window
+			w := append(t.windows[field], v)
+			if len(w) > f.Window {
+				w = w[len(w)-f.Window:]
+			}
+			t.windows[field] = w
+		}
+	}
+	if err != nil {
+		var re *RejectedError
+		if errors.As(err, &re) {
+			if f.rejections[sd.Addr] == nil {
+				f.rejections[sd.Addr] = make(map[string]int)
+			}
+			f.rejections[sd.Addr][re.Reason]++
+		}
+		return sd, err
+	}
+	t.accepted = sd
+	return sd, nil
+}
+
+// Rejections returns the number of rejected measurements of each sensor by reason
+func (f *Filter) Rejections() map[string]map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rejections := make(map[string]map[string]int)
+	for addr, reasons := range f.rejections {
+		rejections[addr] = make(map[string]int)
+		for reason, n := range reasons {
+			rejections[addr][reason] = n
+		}
+	}
+	return rejections
+}
+
+func (f *Filter) check(t *tag, sd sensor.Data, field string, v float64) error {
+	c := f.Checks[field]
+	if c.Min != nil && v < *c.Min {
+		return reject(field, "range", v, "below minimum %g", *c.Min)
+	}
+	if c.Max != nil && v > *c.Max {
+		return reject(field, "range", v, "above maximum %g", *c.Max)
+	}
+	if prev, ok := t.accepted.Value(field); ok && c.MaxRate > 0 {
+		minutes := math.Max(sd.Timestamp.Sub(t.accepted.Timestamp).Minutes(), 1)
+		if math.Abs(v-prev) > c.MaxRate*minutes {
+			return reject(field, "rate", v, "changed from %g in %s", prev, sd.Timestamp.Sub(t.accepted.Timestamp).Round(time.Second))
+		}
+	}
+	if w := t.windows[field]; c.Hampel && f.Window > 0 && len(w) >= f.Window {
+		median := aggregate.Median(w)
+		deviations := make([]float64, len(w))
+		for i, x := range w {
+			deviations[i] = math.Abs(x - median)
+		}
+		limit := math.Max(f.K*madScale*aggregate.Median(deviations), c.MinDeviation)
+		if math.Abs(v-median) > limit {
+			return reject(field, "hampel", v, "deviates from median %g", median)
+		}
+	}
+	return nil
+}
+
+func (f *Filter) fields() []string {
+	var fields []string
+	for field := range f.Checks {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// RejectedError describes why a measurement was rejected
+type RejectedError struct {
+	Field  string
+	Reason string
+	Value  float64
+	Detail string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%s: %s %g %s", ErrRejected, e.Field, e.Value, e.Detail)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return ErrRejected
+}
+
+func reject(field, reason string, v float64, format string, args ...interface{}) error {
+	return &RejectedError{
+		Field:  field,
+		Reason: reason,
+		Value:  v,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
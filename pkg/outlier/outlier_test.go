@@ -0,0 +1,84 @@
+package outlier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+const (
+	backyard = "cc:ca:7e:52:cc:34"
+	upstairs = "fb:e1:b7:04:95:ee"
+)
+
+func TestRange(t *testing.T) {
+	f := &Filter{Checks: map[string]Check{
+		"temperature": {Min: sensor.Float64(-40), Max: sensor.Float64(84)},
+	}}
+	_, err := f.Process(sensor.Data{Addr: backyard, Temperature: sensor.Float64(21.5)})
+	assert.NoError(t, err)
+	_, err = f.Process(sensor.Data{Addr: backyard, Temperature: sensor.Float64(85)})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRejected))
+	_, err = f.Process(sensor.Data{Addr: backyard, Temperature: sensor.Float64(-45)})
+	assert.Error(t, err)
+	_, err = f.Process(sensor.Data{Addr: backyard, Humidity: sensor.Float64(50)})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]int{backyard: {"range": 2}}, f.Rejections())
+}
+
+func TestRate(t *testing.T) {
+	f := &Filter{Checks: map[string]Check{
+		"temperature": {MaxRate: 1},
+	}}
+	tests := []struct {
+		data     sensor.Data
+		rejected bool
+	}{
+		{sensor.Data{Addr: backyard, Temperature: sensor.Float64(20), Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)}, false},
+		// Changes up to the rate are allowed within the first minute
+		{sensor.Data{Addr: backyard, Temperature: sensor.Float64(20.5), Timestamp: time.Date(2020, time.January, 1, 12, 0, 1, 0, time.UTC)}, false},
+		{sensor.Data{Addr: backyard, Temperature: sensor.Float64(30), Timestamp: time.Date(2020, time.January, 1, 12, 0, 2, 0, time.UTC)}, true},
+		// Other tags have their own reference
+		{sensor.Data{Addr: upstairs, Temperature: sensor.Float64(30), Timestamp: time.Date(2020, time.January, 1, 12, 0, 2, 0, time.UTC)}, false},
+		// The rate is relative to the previous accepted measurement
+		{sensor.Data{Addr: backyard, Temperature: sensor.Float64(23), Timestamp: time.Date(2020, time.January, 1, 12, 3, 0, 0, time.UTC)}, false},
+	}
+	for i, tt := range tests {
+		_, err := f.Process(tt.data)
+		assert.Equal(t, tt.rejected, err != nil, "measurement %d: %v", i, err)
+	}
+	assert.Equal(t, map[string]map[string]int{backyard: {"rate": 1}}, f.Rejections())
+}
+
+func TestHampel(t *testing.T) {
+	f := &Filter{
+		Checks: map[string]Check{
+			"temperature": {Hampel: true, MinDeviation: 0.5},
+		},
+		Window: 5,
+		K:      3,
+	}
+	for _, temp := range []float64{20, 20.1, 20, 20.2, 20.1} {
+		_, err := f.Process(sensor.Data{Addr: backyard, Temperature: sensor.Float64(temp)})
+		require.NoError(t, err)
+	}
+	_, err := f.Process(sensor.Data{Addr: backyard, Temperature: sensor.Float64(20.3)})
+	assert.NoError(t, err)
+	_, err = f.Process(sensor.Data{Addr: backyard, Temperature: sensor.Float64(85)})
+	assert.Error(t, err)
+	// A lasting step change is accepted once it dominates the window
+	var accepted bool
+	for i := 0; i < 5; i++ {
+		if _, err := f.Process(sensor.Data{Addr: backyard, Temperature: sensor.Float64(25)}); err == nil {
+			accepted = true
+			break
+		}
+	}
+	assert.True(t, accepted)
+}
@@ -0,0 +1,94 @@
+package psychrometrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Metric is a psychrometric metric derived from temperature, humidity and pressure
+type Metric string
+
+// Supported metrics
+const (
+	MetricAbsoluteHumidity     Metric = "absolute_humidity"
+	MetricMixingRatio          Metric = "mixing_ratio"
+	MetricVaporPressureDeficit Metric = "vapor_pressure_deficit"
+	MetricWetBulb              Metric = "wet_bulb"
+	MetricFrostPoint           Metric = "frost_point"
+	MetricHeatIndex            Metric = "heat_index"
+	MetricHumidex              Metric = "humidex"
+)
+
+// Metrics contains all supported metrics
+var Metrics = []Metric{
+	MetricAbsoluteHumidity,
+	MetricMixingRatio,
+	MetricVaporPressureDeficit,
+	MetricWetBulb,
+	MetricFrostPoint,
+	MetricHeatIndex,
+	MetricHumidex,
+}
+
+// Calculator adds the enabled psychrometric metrics to measurements
+type Calculator struct {
+	metrics map[Metric]bool
+}
+
+// NewCalculator creates a calculator for the given metric names
+func NewCalculator(metrics []string) (*Calculator, error) {
+	c := &Calculator{metrics: make(map[Metric]bool)}
+	for _, name := range metrics {
+		m := Metric(strings.ToLower(strings.TrimSpace(name)))
+		if !isSupported(m) {
+			return nil, fmt.Errorf("unsupported psychrometric metric: %s", name)
+		}
+		c.metrics[m] = true
+	}
+	return c, nil
+}
+
+// Process calculates the enabled metrics for measurements that have both temperature and humidity.
+// Metrics that depend on pressure are skipped for measurements without pressure.
+func (c *Calculator) Process(sd sensor.Data) (sensor.Data, error) {
+	if sd.Temperature == nil || sd.Humidity == nil {
+		return sd, nil
+	}
+	temp := *sd.Temperature
+	humidity := *sd.Humidity
+	if c.metrics[MetricAbsoluteHumidity] {
+		sd.AbsoluteHumidity = sensor.Float64(AbsoluteHumidity(temp, humidity))
+	}
+	if c.metrics[MetricMixingRatio] && sd.Pressure != nil {
+		sd.MixingRatio = sensor.Float64(MixingRatio(temp, humidity, *sd.Pressure))
+	}
+	if c.metrics[MetricVaporPressureDeficit] {
+		sd.VPD = sensor.Float64(VaporPressureDeficit(temp, humidity))
+	}
+	if c.metrics[MetricWetBulb] && sd.Pressure != nil {
+		sd.WetBulb = sensor.Float64(WetBulb(temp, humidity, *sd.Pressure))
+	}
+	if c.metrics[MetricFrostPoint] {
+		if fp, err := FrostPoint(temp, humidity); err == nil {
+			sd.FrostPoint = sensor.Float64(fp)
+		}
+	}
+	if c.metrics[MetricHeatIndex] {
+		sd.HeatIndex = sensor.Float64(HeatIndex(temp, humidity))
+	}
+	if c.metrics[MetricHumidex] {
+		sd.Humidex = sensor.Float64(Humidex(temp, humidity))
+	}
+	return sd, nil
+}
+
+func isSupported(m Metric) bool {
+	for _, s := range Metrics {
+		if s == m {
+			return true
+		}
+	}
+	return false
+}
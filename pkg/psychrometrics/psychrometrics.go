@@ -0,0 +1,95 @@
+package psychrometrics
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/dewpoint"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/temperature"
+)
+
+const (
+	// StandardPressure is the standard atmospheric pressure at sea level in hPa
+	StandardPressure = 1013.25
+	// WaterVaporGasConstant is the specific gas constant of water vapor in J/(kg·K)
+	WaterVaporGasConstant = 461.5
+	// PsychrometerCoefficient is the psychrometer coefficient of a ventilated psychrometer in 1/K
+	PsychrometerCoefficient = 6.62e-4
+)
+
+// VaporPressure calculates water vapor pressure (Pa) from the given temperature (°C) and relative humidity (percent)
+func VaporPressure(temp, humidity float64) float64 {
+	return humidity / 100.0 * dewpoint.SaturationVaporPressure(temperature.Convert(temp, temperature.Celsius, temperature.Kelvin))
+}
+
+// AbsoluteHumidity calculates absolute humidity (g/m³)
+func AbsoluteHumidity(temp, humidity float64) float64 {
+	tempInK := temperature.Convert(temp, temperature.Celsius, temperature.Kelvin)
+	return VaporPressure(temp, humidity) / (WaterVaporGasConstant * tempInK) * 1000.0
+}
+
+// MixingRatio calculates the mass of water vapor per mass of dry air (g/kg) at the given pressure (hPa)
+func MixingRatio(temp, humidity, pressure float64) float64 {
+	e := VaporPressure(temp, humidity)
+	return 0.622 * e / (pressure*100.0 - e) * 1000.0
+}
+
+// VaporPressureDeficit calculates the difference between saturation and actual vapor pressure (kPa)
+func VaporPressureDeficit(temp, humidity float64) float64 {
+	tempInK := temperature.Convert(temp, temperature.Celsius, temperature.Kelvin)
+	pvs := dewpoint.SaturationVaporPressure(tempInK)
+	return (pvs - VaporPressure(temp, humidity)) / 1000.0
+}
+
+// WetBulb calculates wet-bulb temperature (°C) at the given pressure (hPa) by solving the psychrometer equation
+func WetBulb(temp, humidity, pressure float64) float64 {
+	e := VaporPressure(temp, humidity)
+	p := pressure * 100.0
+	f := func(tw float64) float64 {
+		tempInK := temperature.Convert(tw, temperature.Celsius, temperature.Kelvin)
+		return dewpoint.SaturationVaporPressure(tempInK) - PsychrometerCoefficient*p*(temp-tw) - e
+	}
+	lo, hi := temp-60.0, temp
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if f(mid) < 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// FrostPoint calculates the temperature (°C) at which the air becomes saturated with respect to ice
+func FrostPoint(temp, humidity float64) (float64, error) {
+	tempInK := temperature.Convert(temp, temperature.Celsius, temperature.Kelvin)
+	if tempInK < dewpoint.MinTemperature || tempInK > dewpoint.MaxTemperature {
+		return 0, fmt.Errorf("temperature %f out of range", temp)
+	}
+	fpInK, err := dewpoint.Solve(dewpoint.SaturationVaporPressureIce, VaporPressure(temp, humidity), tempInK)
+	return temperature.Convert(fpInK, temperature.Kelvin, temperature.Celsius), err
+}
+
+// HeatIndex calculates the apparent temperature (°C) using the NWS heat index algorithm
+func HeatIndex(temp, humidity float64) float64 {
+	t := temperature.Convert(temp, temperature.Celsius, temperature.Fahrenheit)
+	rh := humidity
+	hi := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+	if (hi+t)/2 >= 80.0 {
+		hi = -42.379 + 2.04901523*t + 10.14333127*rh - 0.22475541*t*rh -
+			0.00683783*t*t - 0.05481717*rh*rh + 0.00122874*t*t*rh +
+			0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+		if rh < 13 && t >= 80 && t <= 112 {
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		} else if rh > 85 && t >= 80 && t <= 87 {
+			hi += (rh - 85) / 10 * (87 - t) / 5
+		}
+	}
+	return temperature.Convert(hi, temperature.Fahrenheit, temperature.Celsius)
+}
+
+// Humidex calculates the Canadian humidex index
+func Humidex(temp, humidity float64) float64 {
+	return temp + 0.5555*(VaporPressure(temp, humidity)/100.0-10.0)
+}
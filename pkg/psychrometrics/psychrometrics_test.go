@@ -0,0 +1,77 @@
+package psychrometrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+func TestAbsoluteHumidity(t *testing.T) {
+	assert.InDelta(t, 11.5, AbsoluteHumidity(25, 50), 0.1)
+}
+
+func TestMixingRatio(t *testing.T) {
+	assert.InDelta(t, 9.9, MixingRatio(25, 50, StandardPressure), 0.1)
+}
+
+func TestVaporPressureDeficit(t *testing.T) {
+	assert.InDelta(t, 1.58, VaporPressureDeficit(25, 50), 0.01)
+	assert.InDelta(t, 0, VaporPressureDeficit(25, 100), 0.001)
+}
+
+func TestWetBulb(t *testing.T) {
+	assert.InDelta(t, 17.9, WetBulb(25, 50, StandardPressure), 0.1)
+	assert.InDelta(t, 25, WetBulb(25, 100, StandardPressure), 0.01)
+}
+
+func TestFrostPoint(t *testing.T) {
+	fp, err := FrostPoint(-5, 80)
+	require.NoError(t, err)
+	assert.InDelta(t, -7.6, fp, 0.1)
+}
+
+func TestHeatIndex(t *testing.T) {
+	assert.InDelta(t, 24.9, HeatIndex(25, 50), 0.1)
+	assert.InDelta(t, 40.4, HeatIndex(32, 70), 0.1)
+}
+
+func TestHumidex(t *testing.T) {
+	assert.InDelta(t, 41.0, Humidex(30, 70), 0.1)
+}
+
+func TestCalculator(t *testing.T) {
+	calc, err := NewCalculator([]string{"absolute_humidity", "mixing_ratio", "wet_bulb", "humidex"})
+	require.NoError(t, err)
+	sd, err := calc.Process(sensor.Data{
+		Temperature: sensor.Float64(25),
+		Humidity:    sensor.Float64(50),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sd.AbsoluteHumidity)
+	assert.InDelta(t, 11.5, *sd.AbsoluteHumidity, 0.1)
+	require.NotNil(t, sd.Humidex)
+	// Mixing ratio and wet bulb require pressure
+	assert.Nil(t, sd.MixingRatio)
+	assert.Nil(t, sd.WetBulb)
+	assert.Nil(t, sd.VPD)
+
+	sd, err = calc.Process(sensor.Data{
+		Temperature: sensor.Float64(25),
+		Humidity:    sensor.Float64(50),
+		Pressure:    sensor.Float64(StandardPressure),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, sd.MixingRatio)
+	require.NotNil(t, sd.WetBulb)
+	assert.InDelta(t, 17.9, *sd.WetBulb, 0.1)
+
+	sd, err = calc.Process(sensor.Data{Temperature: sensor.Float64(25)})
+	require.NoError(t, err)
+	assert.Nil(t, sd.AbsoluteHumidity)
+
+	_, err = NewCalculator([]string{"wind_chill"})
+	assert.Error(t, err)
+}
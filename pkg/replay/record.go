@@ -0,0 +1,78 @@
+package replay
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-ble/ble"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+)
+
+// Record is a recorded BLE advertisement. A recording is a file of records encoded as JSON,
+// one per line.
+type Record struct {
+	Addr             string            `json:"mac"`
+	RSSI             int               `json:"rssi"`
+	Timestamp        time.Time         `json:"ts"`
+	LocalName        string            `json:"local_name,omitempty"`
+	ManufacturerData []byte            `json:"data,omitempty"`
+	ServiceData      map[uint16][]byte `json:"service_data,omitempty"`
+}
+
+// NewRecord records the advertisement as received at the given time
+func NewRecord(a ble.Advertisement, ts time.Time) Record {
+	r := Record{
+		Addr:             a.Addr().String(),
+		RSSI:             a.RSSI(),
+		Timestamp:        ts,
+		LocalName:        a.LocalName(),
+		ManufacturerData: a.ManufacturerData(),
+	}
+	for _, sd := range a.ServiceData() {
+		if len(sd.UUID) != 2 {
+			continue
+		}
+		if r.ServiceData == nil {
+			r.ServiceData = make(map[uint16][]byte)
+		}
+		r.ServiceData[binary.LittleEndian.Uint16(sd.UUID)] = sd.Data
+	}
+	return r
+}
+
+// Recorder is a BLE scanner that records the advertisements of the wrapped scanner that pass
+// the filter before handling them
+type Recorder struct {
+	scanner scanner.BLEScanner
+	logger  *zap.Logger
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewRecorder creates a recorder writing the advertisements to w
+func NewRecorder(s scanner.BLEScanner, w io.Writer, logger *zap.Logger) *Recorder {
+	return &Recorder{
+		scanner: s,
+		logger:  logger,
+		enc:     json.NewEncoder(w),
+	}
+}
+
+func (r *Recorder) Scan(ctx context.Context, allowDup bool, h ble.AdvHandler, f ble.AdvFilter) error {
+	return r.scanner.Scan(ctx, allowDup, func(a ble.Advertisement) {
+		r.mu.Lock()
+		err := r.enc.Encode(NewRecord(a, time.Now()))
+		r.mu.Unlock()
+		if err != nil {
+			r.logger.Error("Failed to record advertisement", zap.Error(err))
+		}
+		h(a)
+	}, f)
+}
@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-ble/ble"
+)
+
+// ErrEnd is returned by Scan when all recorded advertisements have been replayed
+var ErrEnd = errors.New("end of recording")
+
+// Scanner is a BLE scanner that replays recorded advertisements. Speed scales the time between
+// advertisements: 1 replays them in real time, 10 ten times faster and 0 as fast as possible.
+// Each scan continues from where the previous one ended. Measurements read from replayed
+// advertisements keep their recorded timestamps.
+type Scanner struct {
+	Speed float64
+
+	r    io.ReadCloser
+	dec  *json.Decoder
+	next *Record
+	prev time.Time
+}
+
+// Open opens a recording for replaying
+func Open(path string) (*Scanner, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewScanner(f), nil
+}
+
+// NewScanner creates a scanner replaying the recording read from r at real speed
+func NewScanner(r io.ReadCloser) *Scanner {
+	return &Scanner{
+		Speed: 1,
+		r:     r,
+		dec:   json.NewDecoder(r),
+	}
+}
+
+// Scan replays advertisements until the context is done or all of them have been replayed
+func (s *Scanner) Scan(ctx context.Context, allowDup bool, h ble.AdvHandler, f ble.AdvFilter) error {
+	for {
+		if s.next == nil {
+			var r Record
+			if err := s.dec.Decode(&r); err == io.EOF {
+				return ErrEnd
+			} else if err != nil {
+				return fmt.Errorf("invalid recording: %w", err)
+			}
+			s.next = &r
+		}
+		if d := s.delay(s.next.Timestamp); d > 0 {
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		a := advertisement{*s.next}
+		s.prev = s.next.Timestamp
+		s.next = nil
+		if f == nil || f(a) {
+			h(a)
+		}
+	}
+}
+
+// Close closes the recording
+func (s *Scanner) Close() error {
+	return s.r.Close()
+}
+
+func (s *Scanner) delay(ts time.Time) time.Duration {
+	if s.Speed <= 0 || s.prev.IsZero() || !ts.After(s.prev) {
+		return 0
+	}
+	return time.Duration(float64(ts.Sub(s.prev)) / s.Speed)
+}
+
+// advertisement is a replayed BLE advertisement
+type advertisement struct {
+	r Record
+}
+
+func (a advertisement) LocalName() string {
+	return a.r.LocalName
+}
+
+func (a advertisement) ManufacturerData() []byte {
+	return a.r.ManufacturerData
+}
+
+func (a advertisement) ServiceData() []ble.ServiceData {
+	var sd []ble.ServiceData
+	for id, data := range a.r.ServiceData {
+		sd = append(sd, ble.ServiceData{UUID: ble.UUID16(id), Data: data})
+	}
+	return sd
+}
+
+func (a advertisement) Services() []ble.UUID {
+	return nil
+}
+
+func (a advertisement) OverflowService() []ble.UUID {
+	return nil
+}
+
+func (a advertisement) TxPowerLevel() int {
+	return 0
+}
+
+func (a advertisement) Connectable() bool {
+	return false
+}
+
+func (a advertisement) SolicitedService() []ble.UUID {
+	return nil
+}
+
+func (a advertisement) RSSI() int {
+	return a.r.RSSI
+}
+
+func (a advertisement) Addr() ble.Addr {
+	return ble.NewAddr(a.r.Addr)
+}
+
+// Timestamp returns the time the advertisement was recorded
+func (a advertisement) Timestamp() time.Time {
+	return a.r.Timestamp
+}
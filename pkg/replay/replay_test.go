@@ -0,0 +1,227 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-ble/ble"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter/retry"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/lastseen"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/scanner"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockExporter struct {
+	mu   sync.Mutex
+	down bool
+	data []sensor.Data
+}
+
+func (m *mockExporter) Name() string {
+	return "Mock"
+}
+
+func (m *mockExporter) Export(ctx context.Context, data sensor.Data) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.down {
+		return errors.New("connection refused")
+	}
+	m.data = append(m.data, data)
+	return nil
+}
+
+func (m *mockExporter) Close() error {
+	return nil
+}
+
+func (m *mockExporter) setDown(down bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.down = down
+}
+
+func (m *mockExporter) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.data)
+}
+
+func recording(t *testing.T, records ...Record) *Scanner {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	for _, r := range records {
+		require.NoError(t, enc.Encode(r))
+	}
+	return NewScanner(ioutil.NopCloser(buf))
+}
+
+func TestReplay(t *testing.T) {
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Addr: "CC:CA:7E:52:CC:34", RSSI: -70, Timestamp: ts},
+		{Addr: "FB:E1:B7:04:95:EE", RSSI: -80, Timestamp: ts.Add(time.Second)},
+		{Addr: "CC:CA:7E:52:CC:34", RSSI: -71, Timestamp: ts.Add(2 * time.Second)},
+	}
+	for i := range records {
+		data, err := sensor.EncodeSensorFormat3(sensor.Data{
+			Temperature: sensor.Float64(float64(20 + i)),
+			Humidity:    sensor.Float64(60),
+			Pressure:    sensor.Float64(1000),
+		})
+		require.NoError(t, err)
+		records[i].ManufacturerData = data
+	}
+	s := recording(t, records...)
+	s.Speed = 0
+	meas := &scanner.Measurements{
+		BLE:         s,
+		Peripherals: map[string]string{"cc:ca:7e:52:cc:34": "Backyard"},
+		Logger:      zap.NewNop(),
+	}
+	ch := make(chan sensor.Data, 10)
+	err := meas.Stream(context.Background(), ch)
+	assert.Equal(t, ErrEnd, err)
+	close(ch)
+	var measurements []sensor.Data
+	for m := range ch {
+		measurements = append(measurements, m)
+	}
+	require.Len(t, measurements, 2)
+	assert.Equal(t, "cc:ca:7e:52:cc:34", measurements[0].Addr)
+	assert.Equal(t, "Backyard", measurements[0].Name)
+	assert.Equal(t, -70, measurements[0].RSSI)
+	assert.Equal(t, ts, measurements[0].Timestamp)
+	assert.Equal(t, 20.0, *measurements[0].Temperature)
+	assert.Equal(t, ts.Add(2*time.Second), measurements[1].Timestamp)
+	assert.Equal(t, 22.0, *measurements[1].Temperature)
+}
+
+func TestReplayOldRecording(t *testing.T) {
+	data, err := sensor.EncodeSensorFormat3(sensor.Data{
+		Temperature: sensor.Float64(20),
+		Humidity:    sensor.Float64(60),
+		Pressure:    sensor.Float64(1000),
+	})
+	require.NoError(t, err)
+	// Recorded long before the offline timeout and the maximum retry age
+	s := recording(t,
+		Record{Addr: "CC:CA:7E:52:CC:34", Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC), ManufacturerData: data},
+		Record{Addr: "CC:CA:7E:52:CC:34", Timestamp: time.Date(2020, time.January, 1, 12, 1, 0, 0, time.UTC), ManufacturerData: data},
+	)
+	s.Speed = 0
+	dir, err := ioutil.TempDir("", "retry")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mock := &mockExporter{down: true}
+	buf, err := retry.New(mock, retry.Config{Dir: dir, MaxAge: time.Hour, MinBackoff: time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+	registry := lastseen.NewRegistry(5*time.Minute, nil)
+	p := scanner.New(zap.NewNop(), nil)
+	p.SetBLEScanner(s)
+	p.Scheduler = scanner.Continuous{}
+	p.Detectors = []scanner.Detector{registry}
+	p.Exporters = []exporter.Exporter{buf}
+	assert.True(t, errors.Is(p.Run(context.Background()), ErrEnd))
+
+	// The replayed tag is not flagged offline
+	assert.Empty(t, registry.Check(time.Now()))
+	// Failed exports of the replayed measurements are retried instead of expiring
+	assert.Equal(t, 2, buf.Stats().Records)
+	mock.setDown(false)
+	require.Eventually(t, func() bool {
+		return buf.Stats().Records == 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 2, mock.count())
+	assert.Equal(t, 0, buf.Stats().Expired)
+	require.NoError(t, buf.Close())
+}
+
+func TestReplaySpeed(t *testing.T) {
+	s := recording(t,
+		Record{Addr: "CC:CA:7E:52:CC:34", Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)},
+		Record{Addr: "CC:CA:7E:52:CC:34", Timestamp: time.Date(2020, time.January, 1, 12, 1, 0, 0, time.UTC)},
+	)
+	s.Speed = 600
+	count := 0
+	start := time.Now()
+	err := s.Scan(context.Background(), true, func(a ble.Advertisement) {
+		count++
+	}, nil)
+	assert.Equal(t, ErrEnd, err)
+	assert.Equal(t, 2, count)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+}
+
+func TestReplayContinuesAcrossScans(t *testing.T) {
+	s := recording(t,
+		Record{Addr: "CC:CA:7E:52:CC:34", Timestamp: time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)},
+		Record{Addr: "FB:E1:B7:04:95:EE", Timestamp: time.Date(2020, time.January, 1, 13, 0, 0, 0, time.UTC)},
+	)
+	var addrs []string
+	h := func(a ble.Advertisement) {
+		addrs = append(addrs, a.Addr().String())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, s.Scan(ctx, true, h, nil))
+	assert.Equal(t, []string{"cc:ca:7e:52:cc:34"}, addrs)
+	s.Speed = 0
+	assert.Equal(t, ErrEnd, s.Scan(context.Background(), true, h, nil))
+	assert.Equal(t, []string{"cc:ca:7e:52:cc:34", "fb:e1:b7:04:95:ee"}, addrs)
+}
+
+func TestInvalidRecording(t *testing.T) {
+	s := NewScanner(ioutil.NopCloser(strings.NewReader("{\"mac\":")))
+	err := s.Scan(context.Background(), true, func(a ble.Advertisement) {}, nil)
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrEnd, err)
+}
+
+func TestRecorder(t *testing.T) {
+	data, err := sensor.EncodeSensorFormat3(sensor.Data{
+		Temperature: sensor.Float64(20),
+		Humidity:    sensor.Float64(60),
+		Pressure:    sensor.Float64(1000),
+	})
+	require.NoError(t, err)
+	original := Record{
+		Addr:             "cc:ca:7e:52:cc:34",
+		RSSI:             -70,
+		Timestamp:        time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC),
+		ManufacturerData: data,
+		ServiceData:      map[uint16][]byte{sensor.SwitchBotServiceUUID: {0x54, 0, 0x64, 0x05, 0x96, 0x32}},
+	}
+	s := recording(t, original)
+	s.Speed = 0
+	buf := new(bytes.Buffer)
+	rec := NewRecorder(s, buf, zap.NewNop())
+	handled := 0
+	err = rec.Scan(context.Background(), true, func(a ble.Advertisement) {
+		handled++
+	}, func(a ble.Advertisement) bool {
+		return true
+	})
+	assert.Equal(t, ErrEnd, err)
+	assert.Equal(t, 1, handled)
+	var recorded Record
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &recorded))
+	assert.Equal(t, original.Addr, recorded.Addr)
+	assert.Equal(t, original.RSSI, recorded.RSSI)
+	assert.Equal(t, original.ManufacturerData, recorded.ManufacturerData)
+	assert.Equal(t, original.ServiceData, recorded.ServiceData)
+	assert.WithinDuration(t, time.Now(), recorded.Timestamp, time.Minute)
+}
@@ -0,0 +1,207 @@
+package rules
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Operator compares a measured value to the threshold of a rule
+type Operator string
+
+// Supported operators
+const (
+	Above        Operator = ">"
+	AboveOrEqual Operator = ">="
+	Below        Operator = "<"
+	BelowOrEqual Operator = "<="
+)
+
+// Rule fires when the condition on a measurement field holds for a sensor continuously for the duration
+// of For. A firing rule resolves when the value returns past the threshold by more than Hysteresis.
+// After firing, the rule does not notify again before Cooldown has elapsed.
+type Rule struct {
+	Name string
+	// Addrs contains the addresses of the sensors the rule applies to. The rule applies to all sensors if empty.
+	Addrs      []string
+	Field      string
+	Operator   Operator
+	Threshold  float64
+	For        time.Duration
+	Hysteresis float64
+	Cooldown   time.Duration
+}
+
+// Validate checks that the rule is well-formed
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name must be specified")
+	}
+	if !sensor.IsField(r.Field) {
+		return fmt.Errorf("rule %s: unknown field %s", r.Name, r.Field)
+	}
+	switch r.Operator {
+	case Above, AboveOrEqual, Below, BelowOrEqual:
+	default:
+		return fmt.Errorf("rule %s: unsupported operator %s", r.Name, r.Operator)
+	}
+	if r.Hysteresis < 0 {
+		return fmt.Errorf("rule %s: hysteresis must not be negative", r.Name)
+	}
+	return nil
+}
+
+func (r Rule) appliesTo(addr string) bool {
+	if len(r.Addrs) == 0 {
+		return true
+	}
+	for _, a := range r.Addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) active(v float64) bool {
+	switch r.Operator {
+	case Above:
+		return v > r.Threshold
+	case AboveOrEqual:
+		return v >= r.Threshold
+	case Below:
+		return v < r.Threshold
+	case BelowOrEqual:
+		return v <= r.Threshold
+	}
+	return false
+}
+
+func (r Rule) resolved(v float64) bool {
+	if r.Hysteresis == 0 {
+		return !r.active(v)
+	}
+	switch r.Operator {
+	case Above, AboveOrEqual:
+		return v < r.Threshold-r.Hysteresis
+	default:
+		return v > r.Threshold+r.Hysteresis
+	}
+}
+
+// Engine evaluates rules against measurements and emits alert events when rules fire and resolve
+type Engine struct {
+	rules []Rule
+
+	mu     sync.Mutex
+	states map[stateKey]*state
+}
+
+type stateKey struct {
+	rule int
+	addr string
+}
+
+type state struct {
+	pendingSince time.Time
+	firing       bool
+	notified     bool
+	lastNotified time.Time
+}
+
+// NewEngine creates a rules engine after validating the rules
+func NewEngine(rules []Rule) (*Engine, error) {
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Engine{
+		rules:  rules,
+		states: make(map[stateKey]*state),
+	}, nil
+}
+
+// Detect evaluates all rules applying to the measuring sensor
+func (e *Engine) Detect(sd sensor.Data) []sensor.Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var events []sensor.Event
+	for i, r := range e.rules {
+		if !r.appliesTo(sd.Addr) {
+			continue
+		}
+		v, ok := sd.Value(r.Field)
+		if !ok {
+			continue
+		}
+		key := stateKey{rule: i, addr: sd.Addr}
+		st, ok := e.states[key]
+		if !ok {
+			st = new(state)
+			e.states[key] = st
+		}
+		if ev, ok := e.evaluate(r, st, sd, v); ok {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func (e *Engine) evaluate(r Rule, st *state, sd sensor.Data, v float64) (sensor.Event, bool) {
+	ts := sd.Timestamp
+	if st.firing {
+		if !r.resolved(v) {
+			// A rule firing again within the cooldown notifies once the cooldown has elapsed
+			if !st.notified && r.active(v) && ts.Sub(st.lastNotified) >= r.Cooldown {
+				return notify(r, st, sd, v), true
+			}
+			return sensor.Event{}, false
+		}
+		st.firing = false
+		st.pendingSince = time.Time{}
+		if !st.notified {
+			return sensor.Event{}, false
+		}
+		st.notified = false
+		return alert(sensor.EventAlertResolved, r, sd, v), true
+	}
+	if !r.active(v) {
+		st.pendingSince = time.Time{}
+		return sensor.Event{}, false
+	}
+	if st.pendingSince.IsZero() {
+		st.pendingSince = ts
+	}
+	if ts.Sub(st.pendingSince) < r.For {
+		return sensor.Event{}, false
+	}
+	st.firing = true
+	if !st.lastNotified.IsZero() && ts.Sub(st.lastNotified) < r.Cooldown {
+		return sensor.Event{}, false
+	}
+	return notify(r, st, sd, v), true
+}
+
+func notify(r Rule, st *state, sd sensor.Data, v float64) sensor.Event {
+	st.notified = true
+	st.lastNotified = sd.Timestamp
+	return alert(sensor.EventAlert, r, sd, v)
+}
+
+func alert(eventType string, r Rule, sd sensor.Data, v float64) sensor.Event {
+	return sensor.Event{
+		Type:      eventType,
+		Addr:      sd.Addr,
+		Name:      sd.Name,
+		Timestamp: sd.Timestamp,
+		Fields: map[string]interface{}{
+			"rule":      r.Name,
+			"field":     r.Field,
+			"condition": fmt.Sprintf("%s %s %g", r.Field, r.Operator, r.Threshold),
+			"value":     v,
+		},
+	}
+}
@@ -0,0 +1,144 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+const freezer = "cc:ca:7e:52:cc:34"
+
+func TestValidate(t *testing.T) {
+	_, err := NewEngine([]Rule{{Name: "r", Field: "temperature", Operator: Above}})
+	assert.NoError(t, err)
+	_, err = NewEngine([]Rule{{Name: "r", Field: "temp", Operator: Above}})
+	assert.Error(t, err)
+	_, err = NewEngine([]Rule{{Name: "r", Field: "temperature", Operator: "=="}})
+	assert.Error(t, err)
+	_, err = NewEngine([]Rule{{Field: "temperature", Operator: Above}})
+	assert.Error(t, err)
+}
+
+func TestAlert(t *testing.T) {
+	e, err := NewEngine([]Rule{{Name: "freezer-warm", Field: "temperature", Operator: Above, Threshold: -15}})
+	require.NoError(t, err)
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	events := e.Detect(sensor.Data{Addr: freezer, Name: "Freezer", Temperature: sensor.Float64(-13), Timestamp: ts})
+	require.Len(t, events, 1)
+	assert.Equal(t, sensor.Event{
+		Type:      sensor.EventAlert,
+		Addr:      freezer,
+		Name:      "Freezer",
+		Timestamp: ts,
+		Fields: map[string]interface{}{
+			"rule":      "freezer-warm",
+			"field":     "temperature",
+			"condition": "temperature > -15",
+			"value":     -13.0,
+		},
+	}, events[0])
+}
+
+func TestDetect(t *testing.T) {
+	type step struct {
+		addr   string
+		temp   float64
+		minute int
+		events []string
+	}
+	tests := []struct {
+		name  string
+		rule  Rule
+		steps []step
+	}{
+		{
+			name: "duration and hysteresis",
+			rule: Rule{
+				Name:       "freezer-warm",
+				Addrs:      []string{freezer},
+				Field:      "temperature",
+				Operator:   Above,
+				Threshold:  -15,
+				For:        10 * time.Minute,
+				Hysteresis: 1,
+			},
+			steps: []step{
+				{freezer, -14, 0, nil},
+				{freezer, -14, 5, nil},
+				// Condition interrupted, duration restarts
+				{freezer, -16, 6, nil},
+				{freezer, -14, 7, nil},
+				{freezer, -14, 16, nil},
+				{freezer, -13, 17, []string{sensor.EventAlert}},
+				// Fires only once
+				{freezer, -12, 18, nil},
+				// Within hysteresis
+				{freezer, -15.5, 19, nil},
+				{freezer, -16.5, 20, []string{sensor.EventAlertResolved}},
+				// Other sensors are not evaluated
+				{"fb:e1:b7:04:95:ee", 20, 0, nil},
+				{"fb:e1:b7:04:95:ee", 20, 60, nil},
+			},
+		},
+		{
+			name: "cooldown",
+			rule: Rule{
+				Name:      "too-warm",
+				Field:     "temperature",
+				Operator:  Above,
+				Threshold: 80,
+				Cooldown:  time.Hour,
+			},
+			steps: []step{
+				{freezer, 81, 0, []string{sensor.EventAlert}},
+				{freezer, 79, 1, []string{sensor.EventAlertResolved}},
+				// Fires again within cooldown without notifying
+				{freezer, 81, 2, nil},
+				{freezer, 79, 3, nil},
+				{freezer, 81, 61, []string{sensor.EventAlert}},
+			},
+		},
+		{
+			name: "cooldown still firing",
+			rule: Rule{
+				Name:      "too-warm",
+				Field:     "temperature",
+				Operator:  Above,
+				Threshold: 80,
+				Cooldown:  time.Hour,
+			},
+			steps: []step{
+				{freezer, 81, 0, []string{sensor.EventAlert}},
+				{freezer, 79, 1, []string{sensor.EventAlertResolved}},
+				// Fires again within cooldown and keeps firing past it
+				{freezer, 81, 2, nil},
+				{freezer, 82, 30, nil},
+				{freezer, 82, 60, []string{sensor.EventAlert}},
+				{freezer, 82, 90, nil},
+				{freezer, 79, 91, []string{sensor.EventAlertResolved}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEngine([]Rule{tt.rule})
+			require.NoError(t, err)
+			for _, s := range tt.steps {
+				events := e.Detect(sensor.Data{
+					Addr:        s.addr,
+					Temperature: sensor.Float64(s.temp),
+					Timestamp:   time.Date(2020, time.January, 1, 12, s.minute, 0, 0, time.UTC),
+				})
+				var types []string
+				for _, ev := range events {
+					types = append(types, ev.Type)
+				}
+				assert.Equal(t, s.events, types, "%s at minute %d", s.addr, s.minute)
+			}
+		})
+	}
+}
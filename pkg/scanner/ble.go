@@ -13,6 +13,11 @@ type BLEScanner interface {
 type defaultBLEScanner struct {
 }
 
+// NewBLEScanner creates a scanner for advertisements received by the default BLE device
+func NewBLEScanner() BLEScanner {
+	return defaultBLEScanner{}
+}
+
 func (s defaultBLEScanner) Scan(ctx context.Context, allowDup bool, h ble.AdvHandler, f ble.AdvFilter) error {
 	return ble.Scan(ctx, allowDup, h, f)
 }
@@ -1,26 +1,58 @@
 package scanner
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/go-ble/ble"
-	"github.com/niktheblak/ruuvitag-gollector/pkg/dewpoint"
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
-	"github.com/niktheblak/ruuvitag-gollector/pkg/temperature"
 	"go.uber.org/zap"
 )
 
-// Read reads sensor data from advertisement
-func Read(a ble.Advertisement) (sd sensor.Data, err error) {
+// timestamped is implemented by advertisements that carry the time they were received, such
+// as replayed advertisements
+type timestamped interface {
+	Timestamp() time.Time
+}
+
+// Read reads sensor data from advertisement using the matching decoder
+func Read(a ble.Advertisement, decoders *sensor.Registry) (sd sensor.Data, err error) {
 	addr := a.Addr().String()
-	data := a.ManufacturerData()
-	sd, err = sensor.Parse(data)
+	sd, err = decoders.Decode(Advertisement(a))
+	if errors.Is(err, sensor.ErrNoKey) {
+		err = fmt.Errorf("cannot decrypt data from %s: %w", addr, err)
+	}
 	sd.Addr = addr
+	sd.LocalName = a.LocalName()
+	sd.RSSI = a.RSSI()
 	sd.Timestamp = time.Now()
-	sd.DewPoint, _ = dewpoint.Calculate(sd.Temperature, temperature.Celsius, sd.Humidity)
+	if t, ok := a.(timestamped); ok {
+		sd.Timestamp = t.Timestamp()
+	}
+	sd.DewPoint = sensor.DewPoint(sd.Temperature, sd.Humidity)
 	return
 }
 
+// Advertisement converts a BLE advertisement for sensor data decoders
+func Advertisement(a ble.Advertisement) sensor.Advertisement {
+	adv := sensor.Advertisement{
+		Addr:             a.Addr().String(),
+		ManufacturerData: a.ManufacturerData(),
+	}
+	for _, sd := range a.ServiceData() {
+		if len(sd.UUID) != 2 {
+			continue
+		}
+		if adv.ServiceData == nil {
+			adv.ServiceData = make(map[uint16][]byte)
+		}
+		adv.ServiceData[binary.LittleEndian.Uint16(sd.UUID)] = sd.Data
+	}
+	return adv
+}
+
 // LogInvalidData logs invalid BLE advertisement data
 func LogInvalidData(logger *zap.Logger, data []byte, err error) {
 	var header []byte
@@ -32,6 +64,7 @@ func LogInvalidData(logger *zap.Logger, data []byte, err error) {
 	logger.Error("Error while parsing RuuviTag data",
 		zap.Int("len", len(data)),
 		zap.Binary("header", header),
+		zap.Binary("data", data),
 		zap.Error(err),
 	)
 }
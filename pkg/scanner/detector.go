@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Detector detects events from the measurements of sensors
+type Detector interface {
+	Detect(sd sensor.Data) []sensor.Event
+}
+
+// Detect runs the measurement through the given detectors and returns the detected events
+func Detect(sd sensor.Data, detectors []Detector) []sensor.Event {
+	var events []sensor.Event
+	for _, d := range detectors {
+		events = append(events, d.Detect(sd)...)
+	}
+	return events
+}
+
+// ExportEvents exports the events to all exporters supporting events. Errors are logged
+// so that a failing event export does not prevent exporting the measurement.
+func ExportEvents(ctx context.Context, logger *zap.Logger, exporters []exporter.Exporter, events []sensor.Event) {
+	for _, ev := range events {
+		logger.Info("Exporting event", zap.Any("event", ev))
+		for _, e := range exporters {
+			ee, ok := e.(exporter.EventExporter)
+			if !ok {
+				continue
+			}
+			if err := ee.ExportEvent(ctx, ev); err != nil {
+				logger.Error("Failed to report event", zap.String("exporter", e.Name()), zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+type mockDetector struct {
+}
+
+func (m mockDetector) Detect(sd sensor.Data) []sensor.Event {
+	return []sensor.Event{{Type: "test", Addr: sd.Addr}}
+}
+
+type mockEventExporter struct {
+	mockExporter
+	detected []sensor.Event
+}
+
+func (m *mockEventExporter) ExportEvent(ctx context.Context, event sensor.Event) error {
+	m.detected = append(m.detected, event)
+	return nil
+}
+
+func TestExportEvents(t *testing.T) {
+	exp := new(mockEventExporter)
+	events := Detect(sensor.Data{Addr: testAddr1}, []Detector{mockDetector{}, mockDetector{}})
+	assert.Len(t, events, 2)
+	ExportEvents(context.Background(), logger, []exporter.Exporter{new(mockExporter), exp}, events)
+	assert.Equal(t, events, exp.detected)
+}
@@ -6,9 +6,9 @@ import (
 	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
 )
 
-func Filter(peripherals map[string]string) func(ble.Advertisement) bool {
+func Filter(decoders *sensor.Registry, peripherals map[string]string) func(ble.Advertisement) bool {
 	return func(a ble.Advertisement) bool {
-		if !sensor.IsRuuviTag(a.ManufacturerData()) {
+		if _, ok := decoders.Lookup(Advertisement(a)); !ok {
 			return false
 		}
 		if len(peripherals) == 0 {
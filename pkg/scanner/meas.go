@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"errors"
 
 	"github.com/go-ble/ble"
 	"go.uber.org/zap"
@@ -14,37 +15,56 @@ const BufferSize = 128
 type Measurements struct {
 	BLE         BLEScanner
 	Peripherals map[string]string
+	Decoders    *sensor.Registry
+	Processors  []Processor
+	Gateway     string
 	Logger      *zap.Logger
 }
 
 // Channel creates a channel that will receive measurements read from all registered peripherals.
-// The cancel function should be called after the client is done with receiving measurements or wishes
-// to abort the scan.
+// The channel is closed after the context is done.
 func (s *Measurements) Channel(ctx context.Context) chan sensor.Data {
-	if s.Logger == nil {
-		s.Logger = zap.NewNop()
-	}
 	ch := make(chan sensor.Data, BufferSize)
 	go func() {
-		err := s.BLE.Scan(ctx, true, func(a ble.Advertisement) {
-			addr := a.Addr().String()
-			s.Logger.Debug("Read sensor data from device", zap.String("addr", addr))
-			sensorData, err := Read(a)
-			if err != nil {
-				LogInvalidData(s.Logger, a.ManufacturerData(), err)
-				return
-			}
-			sensorData.Name = s.Peripherals[addr]
-			ch <- sensorData
-		}, Filter(s.Peripherals))
-		switch err {
-		case context.Canceled:
-		case context.DeadlineExceeded:
-		case nil:
-		default:
+		if err := s.Stream(ctx, ch); err != nil {
 			s.Logger.Error("Scan failed", zap.Error(err))
 		}
 		close(ch)
 	}()
 	return ch
 }
+
+// Stream sends measurements read from all registered peripherals to the channel until the
+// context is done. Invalid data and measurements rejected by processors are logged and skipped.
+func (s *Measurements) Stream(ctx context.Context, ch chan<- sensor.Data) error {
+	if s.Logger == nil {
+		s.Logger = zap.NewNop()
+	}
+	if s.Decoders == nil {
+		s.Decoders = sensor.NewRegistry(sensor.Ruuvi{})
+	}
+	err := s.BLE.Scan(ctx, true, func(a ble.Advertisement) {
+		addr := a.Addr().String()
+		s.Logger.Debug("Read sensor data from device", zap.String("addr", addr))
+		sensorData, err := Read(a, s.Decoders)
+		if err != nil {
+			LogInvalidData(s.Logger, a.ManufacturerData(), err)
+			return
+		}
+		sensorData.Name = s.Peripherals[addr]
+		sensorData.Gateway = s.Gateway
+		sensorData, err = Process(sensorData, s.Processors)
+		if err != nil {
+			LogInvalidData(s.Logger, a.ManufacturerData(), err)
+			return
+		}
+		select {
+		case ch <- sensorData:
+		case <-ctx.Done():
+		}
+	}, Filter(s.Decoders, s.Peripherals))
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,203 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-ble/ble"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/aggregate"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Source produces measurements
+type Source interface {
+	// Stream sends measurements to the channel until the context is done
+	Stream(ctx context.Context, ch chan<- sensor.Data) error
+}
+
+// Pipeline reads measurements from a source, runs them through the processors and detectors
+// and exports them to the exporters. The scheduler decides when and for how long the source
+// is scanned. By default measurements are read from BLE advertisements.
+type Pipeline struct {
+	Source    Source
+	Scheduler Scheduler
+	Detectors []Detector
+	Exporters []exporter.Exporter
+	// Aggregate makes each scan last until its context is done and exports the statistics
+	// of the measurements of each sensor at the end of the scan instead of the measurements
+	Aggregate bool
+
+	logger      *zap.Logger
+	device      ble.Device
+	peripherals map[string]string
+	dev         DeviceCreator
+	meas        *Measurements
+}
+
+// New creates a pipeline reading measurements of the given peripherals from BLE advertisements.
+// Measurements of all nearby sensors are read if peripherals is empty.
+func New(logger *zap.Logger, peripherals map[string]string) *Pipeline {
+	meas := &Measurements{
+		BLE:         defaultBLEScanner{},
+		Peripherals: peripherals,
+		Logger:      logger,
+	}
+	return &Pipeline{
+		Source:      meas,
+		logger:      logger,
+		peripherals: peripherals,
+		dev:         defaultDeviceCreator{},
+		meas:        meas,
+	}
+}
+
+// SetDecoders sets the decoders used for reading sensor data from advertisements
+func (p *Pipeline) SetDecoders(decoders *sensor.Registry) {
+	p.meas.Decoders = decoders
+}
+
+// SetProcessors sets the processors applied to measurements before they are exported
+func (p *Pipeline) SetProcessors(processors []Processor) {
+	p.meas.Processors = processors
+}
+
+// SetGateway sets the gateway ID added to all measurements received by this pipeline
+func (p *Pipeline) SetGateway(gateway string) {
+	p.meas.Gateway = gateway
+}
+
+// SetBLEScanner sets the scanner producing the advertisements that measurements are read from
+func (p *Pipeline) SetBLEScanner(s BLEScanner) {
+	p.meas.BLE = s
+}
+
+// Init initializes the BLE device
+func (p *Pipeline) Init(device string) error {
+	d, err := p.dev.NewDevice(device)
+	if err != nil {
+		return fmt.Errorf("failed to initialize device %s: %w", device, err)
+	}
+	p.device = d
+	if len(p.peripherals) > 0 {
+		p.logger.Info("Reading from peripherals", zap.Any("peripherals", p.peripherals))
+	} else {
+		p.logger.Info("Reading from all nearby BLE peripherals")
+	}
+	return nil
+}
+
+// Run scans measurements on the schedule of the scheduler until the schedule completes or
+// the context is done. Cancelling the context is not an error.
+func (p *Pipeline) Run(ctx context.Context) error {
+	if p.Scheduler == nil {
+		return fmt.Errorf("scheduler must be set")
+	}
+	return p.Scheduler.Schedule(ctx, p.scan)
+}
+
+// Close stops the BLE device and closes the processors implementing io.Closer and the exporters
+func (p *Pipeline) Close() {
+	if p.device != nil {
+		if err := p.device.Stop(); err != nil {
+			p.logger.Error("Error while stopping device", zap.Error(err))
+		}
+	}
+	for _, pr := range p.meas.Processors {
+		if c, ok := pr.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				p.logger.Error("Failed to close processor", zap.Error(err))
+			}
+		}
+	}
+	for _, e := range p.Exporters {
+		if err := e.Close(); err != nil {
+			p.logger.Error("Failed to close exporter", zap.String("exporter", e.Name()), zap.Error(err))
+		}
+	}
+}
+
+// scan exports measurements from the source until the context is done. If untilAllSeen is
+// set and peripherals are configured, the scan ends once all of them have been seen.
+func (p *Pipeline) scan(ctx context.Context, untilAllSeen bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch := make(chan sensor.Data, BufferSize)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- p.Source.Stream(ctx, ch)
+		close(ch)
+	}()
+	var agg *aggregate.Aggregator
+	if p.Aggregate {
+		agg = aggregate.New(time.Now())
+	}
+	seen := make(map[string]bool)
+	for m := range ch {
+		if ctx.Err() != nil {
+			// Drain the channel until the source has stopped
+			continue
+		}
+		if agg != nil {
+			p.logger.Debug("Aggregating measurement", zap.Any("data", m))
+			p.exportEvents(ctx, m)
+			agg.Add(m)
+			continue
+		}
+		p.export(ctx, m)
+		seen[m.Addr] = true
+		if untilAllSeen && len(p.peripherals) > 0 && ContainsKeys(p.peripherals, seen) {
+			cancel()
+		}
+	}
+	if agg != nil {
+		p.exportAggregates(agg.Flush(time.Now()))
+	}
+	err := <-errc
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
+
+func (p *Pipeline) exportEvents(ctx context.Context, m sensor.Data) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	ExportEvents(ctx, p.logger, p.Exporters, Detect(m, p.Detectors))
+}
+
+func (p *Pipeline) export(ctx context.Context, m sensor.Data) {
+	p.logger.Info("Exporting measurement", zap.Any("data", m))
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	ExportEvents(ctx, p.logger, p.Exporters, Detect(m, p.Detectors))
+	for _, e := range p.Exporters {
+		if err := e.Export(ctx, m); err != nil {
+			p.logger.Error("Failed to report measurement", zap.String("exporter", e.Name()), zap.Error(err))
+		}
+	}
+}
+
+// exportAggregates exports the aggregates to all exporters supporting aggregates. The scan
+// context has expired by the time the scan ends so a new context is used.
+func (p *Pipeline) exportAggregates(aggregates []sensor.Aggregate) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, agg := range aggregates {
+		p.logger.Info("Exporting aggregate", zap.Any("aggregate", agg))
+		for _, e := range p.Exporters {
+			ae, ok := e.(exporter.AggregateExporter)
+			if !ok {
+				continue
+			}
+			if err := ae.ExportAggregate(ctx, agg); err != nil {
+				p.logger.Error("Failed to report aggregate", zap.String("exporter", e.Name()), zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ble/ble"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/exporter"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+const (
+	testAddr1 = "cc:ca:7e:52:cc:34"
+	testAddr2 = "fb:e1:b7:04:95:ee"
+	testAddr3 = "e8:e0:c6:0b:b8:c5"
+)
+
+var (
+	testData = sensor.Data{
+		Temperature:    sensor.Float64(55),
+		Humidity:       sensor.Float64(60),
+		Pressure:       sensor.Float64(510),
+		BatteryVoltage: 0.5,
+	}
+	testManufacturerData []byte
+	peripherals = map[string]string{
+		testAddr1: "Test",
+	}
+	testAdvertisement mockAdvertisement
+	logger            *zap.Logger
+)
+
+func init() {
+	logger = zap.NewNop()
+	var err error
+	testManufacturerData, err = sensor.EncodeSensorFormat3(testData)
+	if err != nil {
+		panic(err)
+	}
+	testAdvertisement = mockAdvertisement{
+		addr:             testAddr1,
+		manufacturerData: testManufacturerData,
+		rssi:             -75,
+	}
+}
+
+func TestScanOnce(t *testing.T) {
+	p := New(logger, peripherals)
+	exp := new(mockExporter)
+	p.Exporters = []exporter.Exporter{exp}
+	p.Scheduler = Once{}
+	p.SetBLEScanner(NewMockBLEScanner(testAdvertisement))
+	p.dev = mockDeviceCreator{device: mockDevice{}}
+	p.SetGateway("raspberrypi")
+	err := p.Init("default")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = p.Run(ctx)
+	require.NoError(t, err)
+	// Once returns as soon as all peripherals have been seen
+	assert.NoError(t, ctx.Err())
+	require.Len(t, exp.events, 1)
+	e := exp.events[0]
+	assert.Equal(t, "Test", e.Name)
+	assert.Equal(t, testAddr1, e.Addr)
+	assert.Equal(t, sensor.Float64(55.0), e.Temperature)
+	assert.Equal(t, sensor.Float64(60.0), e.Humidity)
+	assert.Equal(t, sensor.Float64(510.0), e.Pressure)
+	assert.Equal(t, 0.5, e.BatteryVoltage)
+	assert.Equal(t, -75, e.RSSI)
+	assert.Equal(t, testAddr1, e.LocalName)
+	assert.Equal(t, "raspberrypi", e.Gateway)
+}
+
+func TestScanWithInterval(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	peripherals := map[string]string{
+		testAddr1: "Backyard",
+		testAddr2: "Upstairs",
+		testAddr3: "Downstairs",
+	}
+	p := New(logger, peripherals)
+	defer p.Close()
+	exp := new(mockExporter)
+	p.Exporters = []exporter.Exporter{exp}
+	p.Scheduler = Interval{Interval: 100 * time.Millisecond}
+	p.SetBLEScanner(NewMockBLEScanner(
+		mockAdvertisement{
+			addr:             testAddr1,
+			manufacturerData: testManufacturerData,
+		},
+		mockAdvertisement{
+			addr:             testAddr2,
+			manufacturerData: testManufacturerData,
+		},
+		mockAdvertisement{
+			addr:             testAddr3,
+			manufacturerData: testManufacturerData,
+		},
+	))
+	p.dev = mockDeviceCreator{device: mockDevice{}}
+	err := p.Init("default")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err = p.Run(ctx)
+	require.NoError(t, err)
+	require.Len(t, exp.events, 3)
+	e := exp.events[0]
+	assert.Equal(t, "Backyard", e.Name)
+	assert.Equal(t, testAddr1, e.Addr)
+	assert.Equal(t, sensor.Float64(55.0), e.Temperature)
+	assert.Equal(t, sensor.Float64(60.0), e.Humidity)
+	assert.Equal(t, sensor.Float64(510.0), e.Pressure)
+	assert.Equal(t, 0.5, e.BatteryVoltage)
+}
+
+func TestScanWithIntervalAggregate(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	p := New(logger, peripherals)
+	defer p.Close()
+	p.Aggregate = true
+	exp := new(mockExporter)
+	p.Exporters = []exporter.Exporter{exp}
+	p.Scheduler = Interval{Interval: 100 * time.Millisecond}
+	p.SetBLEScanner(NewMockBLEScanner(testAdvertisement, testAdvertisement))
+	p.dev = mockDeviceCreator{device: mockDevice{}}
+	err := p.Init("default")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	err = p.Run(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, exp.events)
+	require.Len(t, exp.aggregates, 2)
+	agg := exp.aggregates[0]
+	assert.Equal(t, "Test", agg.Name)
+	assert.Equal(t, testAddr1, agg.Addr)
+	assert.Equal(t, 1, agg.Count)
+	assert.Equal(t, sensor.Stats{Count: 1, Min: 55, Max: 55, Mean: 55, Median: 55, Last: 55}, agg.Fields["temperature"])
+	assert.True(t, agg.End.After(agg.Start))
+}
+
+func TestScanContinuously(t *testing.T) {
+	p := New(logger, peripherals)
+	defer p.Close()
+	exp := new(mockExporter)
+	p.Exporters = []exporter.Exporter{exp}
+	p.Scheduler = Continuous{}
+	p.SetBLEScanner(NewMockBLEScanner(testAdvertisement))
+	p.dev = mockDeviceCreator{device: mockDevice{}}
+	err := p.Init("default")
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = p.Run(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, exp.events)
+	e := exp.events[0]
+	assert.Equal(t, "Test", e.Name)
+	assert.Equal(t, testAddr1, e.Addr)
+	assert.Equal(t, sensor.Float64(55.0), e.Temperature)
+	assert.Equal(t, sensor.Float64(60.0), e.Humidity)
+	assert.Equal(t, sensor.Float64(510.0), e.Pressure)
+	assert.Equal(t, 0.5, e.BatteryVoltage)
+}
+
+func TestCloseClosesProcessors(t *testing.T) {
+	p := New(logger, peripherals)
+	proc := new(mockProcessor)
+	p.SetProcessors([]Processor{proc})
+	p.Close()
+	assert.True(t, proc.closed)
+}
+
+func TestRunReturnsSourceErrors(t *testing.T) {
+	p := New(logger, peripherals)
+	p.Scheduler = Continuous{}
+	p.SetBLEScanner(failingBLEScanner{})
+	err := p.Run(context.Background())
+	assert.EqualError(t, err, "device disconnected")
+	p.Scheduler = nil
+	assert.Error(t, p.Run(context.Background()))
+}
+
+type failingBLEScanner struct {
+}
+
+func (s failingBLEScanner) Scan(ctx context.Context, allowDup bool, h ble.AdvHandler, f ble.AdvFilter) error {
+	return errors.New("device disconnected")
+}
@@ -0,0 +1,22 @@
+package scanner
+
+import (
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Processor transforms measurements after they have been read from a sensor and before they are exported
+type Processor interface {
+	Process(sd sensor.Data) (sensor.Data, error)
+}
+
+// Process runs the measurement through the given processors in order
+func Process(sd sensor.Data, processors []Processor) (sensor.Data, error) {
+	var err error
+	for _, p := range processors {
+		sd, err = p.Process(sd)
+		if err != nil {
+			return sd, err
+		}
+	}
+	return sd, nil
+}
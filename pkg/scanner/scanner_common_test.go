@@ -92,6 +92,7 @@ func (m *mockBLEScanner) Scan(ctx context.Context, allowDup bool, h ble.AdvHandl
 type mockAdvertisement struct {
 	manufacturerData []byte
 	addr             string
+	rssi             int
 }
 
 func (m mockAdvertisement) Addr() ble.Addr {
@@ -131,7 +132,7 @@ func (m mockAdvertisement) SolicitedService() []ble.UUID {
 }
 
 func (m mockAdvertisement) RSSI() int {
-	return 0
+	return m.rssi
 }
 
 func (m mockAdvertisement) Address() ble.Addr {
@@ -139,7 +140,8 @@ func (m mockAdvertisement) Address() ble.Addr {
 }
 
 type mockExporter struct {
-	events []sensor.Data
+	events     []sensor.Data
+	aggregates []sensor.Aggregate
 }
 
 func (m *mockExporter) Name() string {
@@ -151,6 +153,24 @@ func (m *mockExporter) Export(ctx context.Context, data sensor.Data) error {
 	return nil
 }
 
+func (m *mockExporter) ExportAggregate(ctx context.Context, agg sensor.Aggregate) error {
+	m.aggregates = append(m.aggregates, agg)
+	return nil
+}
+
 func (m *mockExporter) Close() error {
 	return nil
 }
+
+type mockProcessor struct {
+	closed bool
+}
+
+func (m *mockProcessor) Process(sd sensor.Data) (sensor.Data, error) {
+	return sd, nil
+}
+
+func (m *mockProcessor) Close() error {
+	m.closed = true
+	return nil
+}
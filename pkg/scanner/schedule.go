@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/evenminutes"
+)
+
+// ScanFunc scans measurements until the context is done. If untilAllSeen is set, the scan
+// ends as soon as all configured peripherals have been seen.
+type ScanFunc func(ctx context.Context, untilAllSeen bool) error
+
+// Scheduler decides when and for how long measurements are scanned
+type Scheduler interface {
+	// Schedule runs scans until the schedule completes or the context is done
+	Schedule(ctx context.Context, scan ScanFunc) error
+}
+
+// Once scans until all peripherals have been seen once
+type Once struct {
+}
+
+func (s Once) Schedule(ctx context.Context, scan ScanFunc) error {
+	return scan(ctx, true)
+}
+
+// Interval scans at even multiples of the interval. Each scan lasts until all peripherals
+// have been seen or the interval ends.
+type Interval struct {
+	Interval time.Duration
+}
+
+func (s Interval) Schedule(ctx context.Context, scan ScanFunc) error {
+	if s.Interval <= 0 {
+		return fmt.Errorf("scan interval must be greater than zero")
+	}
+	select {
+	case <-time.After(evenminutes.Until(time.Now(), s.Interval)):
+	case <-ctx.Done():
+		return nil
+	}
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		scanCtx, cancel := context.WithTimeout(ctx, s.Interval)
+		err := scan(scanCtx, true)
+		cancel()
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Continuous scans until the context is done, exporting measurements as they are received
+type Continuous struct {
+}
+
+func (s Continuous) Schedule(ctx context.Context, scan ScanFunc) error {
+	return scan(ctx, false)
+}
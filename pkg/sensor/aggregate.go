@@ -0,0 +1,28 @@
+package sensor
+
+import (
+	"time"
+)
+
+// Stats contains statistics of the values of a measurement field over an aggregation window
+type Stats struct {
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stddev"`
+	Last   float64 `json:"last"`
+}
+
+// Aggregate contains the statistics of the measurements of a sensor over an aggregation window.
+// Fields are keyed by the JSON name of the measurement field, such as "temperature".
+type Aggregate struct {
+	Addr    string           `json:"mac"`
+	Name    string           `json:"name"`
+	Gateway string           `json:"gateway,omitempty"`
+	Start   time.Time        `json:"start"`
+	End     time.Time        `json:"end"`
+	Count   int              `json:"count"`
+	Fields  map[string]Stats `json:"fields"`
+}
@@ -0,0 +1,67 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// EnvironmentalSensingUUID is the 16-bit UUID of the environmental sensing service
+const EnvironmentalSensingUUID = 0x181A
+
+/* Payload (ATC1441 format, big endian):
+Byte    Explanation
+---------------------------------------
+0–5 	MAC address
+6–7 	Temperature (16bit signed, in 0.1 degrees)
+8		Humidity (8bit unsigned, in %)
+9		Battery level (8bit unsigned, in %)
+10–11	Battery voltage (16bit unsigned, in mV)
+12		Frame counter
+
+Payload (pvvx custom format, little endian):
+Byte    Explanation
+---------------------------------------
+0–5 	MAC address (reversed)
+6–7 	Temperature (16bit signed, in 0.01 degrees)
+8–9		Humidity (16bit unsigned, in 0.01 %)
+10–11	Battery voltage (16bit unsigned, in mV)
+12		Battery level (8bit unsigned, in %)
+13		Measurement counter
+14		Flags
+*/
+
+// ATC decodes the environmental sensing service data broadcast by Xiaomi LYWSD03MMC
+// thermometers running the ATC or pvvx custom firmware
+type ATC struct {
+}
+
+func (d ATC) Name() string {
+	return "ATC/pvvx"
+}
+
+func (d ATC) Match() Match {
+	return Match{ServiceUUID: EnvironmentalSensingUUID}
+}
+
+func (d ATC) Decode(adv Advertisement) (sd Data, err error) {
+	data := adv.ServiceData[EnvironmentalSensingUUID]
+	switch len(data) {
+	case 13:
+		sd.Temperature = Float64(float64(int16(binary.BigEndian.Uint16(data[6:8]))) / 10.0)
+		sd.Humidity = Float64(float64(data[8]))
+		sd.BatteryLevel = Float64(float64(data[9]))
+		sd.BatteryVoltage = float64(binary.BigEndian.Uint16(data[10:12])) / 1000.0
+		sd.MeasurementNumber = Int(int(data[12]))
+		sd.SequenceModulus = 256
+	case 15:
+		sd.Temperature = Float64(float64(int16(binary.LittleEndian.Uint16(data[6:8]))) / 100.0)
+		sd.Humidity = Float64(float64(binary.LittleEndian.Uint16(data[8:10])) / 100.0)
+		sd.BatteryVoltage = float64(binary.LittleEndian.Uint16(data[10:12])) / 1000.0
+		sd.BatteryLevel = Float64(float64(data[12]))
+		sd.MeasurementNumber = Int(int(data[13]))
+		sd.SequenceModulus = 256
+	default:
+		err = fmt.Errorf("unknown ATC/pvvx data length: %d", len(data))
+	}
+	return
+}
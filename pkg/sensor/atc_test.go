@@ -0,0 +1,30 @@
+package sensor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeATC1441(t *testing.T) {
+	data := []byte{0xA4, 0xC1, 0x38, 0x01, 0x02, 0x03, 0x00, 0xD7, 0x37, 0x5A, 0x0B, 0xB8, 0x2A}
+	sd, err := ATC{}.Decode(Advertisement{ServiceData: map[uint16][]byte{EnvironmentalSensingUUID: data}})
+	require.NoError(t, err)
+	assert.Equal(t, Float64(21.5), sd.Temperature)
+	assert.Equal(t, Float64(55.0), sd.Humidity)
+	assert.Equal(t, 3.0, sd.BatteryVoltage)
+	assert.Equal(t, Float64(90), sd.BatteryLevel)
+	assert.Equal(t, Int(42), sd.MeasurementNumber)
+}
+
+func TestDecodePVVX(t *testing.T) {
+	data := []byte{0x03, 0x02, 0x01, 0x38, 0xC1, 0xA4, 0x3E, 0xF8, 0x7B, 0x15, 0xB8, 0x0B, 0x5A, 0x2A, 0x00}
+	sd, err := ATC{}.Decode(Advertisement{ServiceData: map[uint16][]byte{EnvironmentalSensingUUID: data}})
+	require.NoError(t, err)
+	assert.Equal(t, Float64(-19.86), sd.Temperature)
+	assert.Equal(t, Float64(54.99), sd.Humidity)
+	assert.Equal(t, 3.0, sd.BatteryVoltage)
+	assert.Equal(t, Float64(90), sd.BatteryLevel)
+	assert.Equal(t, Int(42), sd.MeasurementNumber)
+}
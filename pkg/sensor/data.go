@@ -4,19 +4,72 @@ import (
 	"time"
 )
 
+// Data is a measurement read from a sensor. Pointer fields are nil when the
+// sensor does not report the value or reports it as invalid. MovementModulus and
+// SequenceModulus are the number of distinct movement counter and measurement
+// number values of the data format, 0 if the sensor does not have the counter.
 type Data struct {
-	Addr              string    `json:"mac"`
-	Name              string    `json:"name"`
-	Temperature       float64   `json:"temperature"`
-	Humidity          float64   `json:"humidity"`
-	DewPoint          float64   `json:"dew_point,omitempty"`
-	Pressure          float64   `json:"pressure"`
-	BatteryVoltage    float64   `json:"battery_voltage,omitempty"`
-	TxPower           int       `json:"tx_power,omitempty"`
-	AccelerationX     int       `json:"acceleration_x"`
-	AccelerationY     int       `json:"acceleration_y"`
-	AccelerationZ     int       `json:"acceleration_z"`
-	MovementCounter   int       `json:"movement_counter"`
-	MeasurementNumber int       `json:"measurement_number"`
-	Timestamp         time.Time `json:"ts"`
+	Addr                 string    `json:"mac"`
+	Name                 string    `json:"name"`
+	LocalName            string    `json:"local_name,omitempty"`
+	Gateway              string    `json:"gateway,omitempty"`
+	RSSI                 int       `json:"rssi,omitempty"`
+	Temperature          *float64  `json:"temperature,omitempty"`
+	Humidity             *float64  `json:"humidity,omitempty"`
+	DewPoint             *float64  `json:"dew_point,omitempty"`
+	CondensationMargin   *float64  `json:"condensation_margin,omitempty"`
+	CondensationRisk     *bool     `json:"condensation_risk,omitempty"`
+	MoldIndex            *float64  `json:"mold_index,omitempty"`
+	Pressure             *float64  `json:"pressure,omitempty"`
+	SeaLevelPressure     *float64  `json:"sea_level_pressure,omitempty"`
+	PressureTendency     *float64  `json:"pressure_tendency,omitempty"`
+	PressureTendencyCode *int      `json:"pressure_tendency_code,omitempty"`
+	PressureTrend        string    `json:"pressure_trend,omitempty"`
+	Forecast             string    `json:"forecast,omitempty"`
+	ForecastCode         *int      `json:"forecast_code,omitempty"`
+	BatteryVoltage       float64   `json:"battery_voltage,omitempty"`
+	BatteryLevel         *float64  `json:"battery_level,omitempty"`
+	TxPower              int       `json:"tx_power,omitempty"`
+	AccelerationX        *int      `json:"acceleration_x,omitempty"`
+	AccelerationY        *int      `json:"acceleration_y,omitempty"`
+	AccelerationZ        *int      `json:"acceleration_z,omitempty"`
+	Pitch                *float64  `json:"pitch,omitempty"`
+	Roll                 *float64  `json:"roll,omitempty"`
+	GForce               *float64  `json:"g_force,omitempty"`
+	OrientationChanged   *bool     `json:"orientation_changed,omitempty"`
+	MovementCounter      int       `json:"movement_counter"`
+	MovementModulus      int       `json:"-"`
+	MeasurementNumber    *int      `json:"measurement_number,omitempty"`
+	SequenceModulus      int       `json:"-"`
+	PM1                  *float64  `json:"pm1_0,omitempty"`
+	PM25                 *float64  `json:"pm2_5,omitempty"`
+	PM4                  *float64  `json:"pm4_0,omitempty"`
+	PM10                 *float64  `json:"pm10_0,omitempty"`
+	CO2                  *int      `json:"co2,omitempty"`
+	VOCIndex             *int      `json:"voc_index,omitempty"`
+	NOXIndex             *int      `json:"nox_index,omitempty"`
+	Luminosity           *float64  `json:"luminosity,omitempty"`
+	AbsoluteHumidity     *float64  `json:"absolute_humidity,omitempty"`
+	MixingRatio          *float64  `json:"mixing_ratio,omitempty"`
+	VPD                  *float64  `json:"vapor_pressure_deficit,omitempty"`
+	WetBulb              *float64  `json:"wet_bulb,omitempty"`
+	FrostPoint           *float64  `json:"frost_point,omitempty"`
+	HeatIndex            *float64  `json:"heat_index,omitempty"`
+	Humidex              *float64  `json:"humidex,omitempty"`
+	Timestamp            time.Time `json:"ts"`
+}
+
+// Float64 returns a pointer to the given float64 value
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// Bool returns a pointer to the given bool value
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Int returns a pointer to the given int value
+func Int(v int) *int {
+	return &v
 }
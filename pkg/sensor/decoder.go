@@ -0,0 +1,109 @@
+package sensor
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// RuuviManufacturerID is the Bluetooth SIG company identifier of Ruuvi Innovations
+const RuuviManufacturerID = 0x0499
+
+// Advertisement contains the parts of a BLE advertisement used for decoding sensor data
+type Advertisement struct {
+	Addr             string
+	ManufacturerData []byte
+	// ServiceData contains service data payloads by 16-bit service UUID
+	ServiceData map[uint16][]byte
+}
+
+// ManufacturerID returns the company identifier from the manufacturer data
+func (a Advertisement) ManufacturerID() (uint16, bool) {
+	if len(a.ManufacturerData) < 2 {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(a.ManufacturerData), true
+}
+
+// Match specifies the advertisements a decoder handles. Decoders match either on
+// the manufacturer ID of the manufacturer data or on a 16-bit service data UUID.
+type Match struct {
+	ManufacturerID uint16
+	ServiceUUID    uint16
+}
+
+// Decoder decodes sensor data from BLE advertisements
+type Decoder interface {
+	Name() string
+	Match() Match
+	Decode(adv Advertisement) (Data, error)
+}
+
+// Registry dispatches advertisements to registered decoders
+type Registry struct {
+	byManufacturer map[uint16]Decoder
+	byService      map[uint16]Decoder
+}
+
+// NewRegistry creates a registry with the given decoders
+func NewRegistry(decoders ...Decoder) *Registry {
+	r := &Registry{
+		byManufacturer: make(map[uint16]Decoder),
+		byService:      make(map[uint16]Decoder),
+	}
+	for _, d := range decoders {
+		r.Register(d)
+	}
+	return r
+}
+
+// Register registers a decoder, replacing any decoder with the same match
+func (r *Registry) Register(d Decoder) {
+	m := d.Match()
+	if m.ServiceUUID != 0 {
+		r.byService[m.ServiceUUID] = d
+	} else {
+		r.byManufacturer[m.ManufacturerID] = d
+	}
+}
+
+// Lookup returns the decoder for the given advertisement
+func (r *Registry) Lookup(adv Advertisement) (Decoder, bool) {
+	if id, ok := adv.ManufacturerID(); ok {
+		if d, ok := r.byManufacturer[id]; ok {
+			return d, true
+		}
+	}
+	for uuid := range adv.ServiceData {
+		if d, ok := r.byService[uuid]; ok {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// Decode decodes the advertisement with the matching decoder
+func (r *Registry) Decode(adv Advertisement) (Data, error) {
+	d, ok := r.Lookup(adv)
+	if !ok {
+		return Data{}, fmt.Errorf("no decoder for advertisement from %s", adv.Addr)
+	}
+	return d.Decode(adv)
+}
+
+// Ruuvi decodes RuuviTag manufacturer data
+type Ruuvi struct {
+	// Keys contains decryption keys for RuuviTags using encrypted data formats by address
+	Keys map[string][]byte
+}
+
+func (d Ruuvi) Name() string {
+	return "RuuviTag"
+}
+
+func (d Ruuvi) Match() Match {
+	return Match{ManufacturerID: RuuviManufacturerID}
+}
+
+func (d Ruuvi) Decode(adv Advertisement) (Data, error) {
+	return ParseWithKey(adv.ManufacturerData, d.Keys[adv.Addr])
+}
@@ -0,0 +1,29 @@
+package sensor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryDispatch(t *testing.T) {
+	r := NewRegistry(Ruuvi{}, ATC{}, Govee{}, SwitchBot{})
+	d, ok := r.Lookup(Advertisement{ManufacturerData: testData})
+	require.True(t, ok)
+	assert.Equal(t, "RuuviTag", d.Name())
+	d, ok = r.Lookup(Advertisement{ManufacturerData: []byte{0x88, 0xEC, 0x00, 0x03, 0x6D, 0x2D, 0x64, 0x00}})
+	require.True(t, ok)
+	assert.Equal(t, "Govee H5075", d.Name())
+	d, ok = r.Lookup(Advertisement{ServiceData: map[uint16][]byte{SwitchBotServiceUUID: {0x54, 0x00, 0x64, 0x05, 0x96, 0x2D}}})
+	require.True(t, ok)
+	assert.Equal(t, "SwitchBot Meter", d.Name())
+	_, ok = r.Lookup(Advertisement{ManufacturerData: []byte{0x4C, 0x00, 0x02, 0x15}})
+	assert.False(t, ok)
+}
+
+func TestRegistryWithoutDecoder(t *testing.T) {
+	r := NewRegistry(Ruuvi{})
+	_, err := r.Decode(Advertisement{ServiceData: map[uint16][]byte{EnvironmentalSensingUUID: make([]byte, 13)}})
+	assert.Error(t, err)
+}
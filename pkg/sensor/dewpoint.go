@@ -0,0 +1,19 @@
+package sensor
+
+import (
+	"github.com/niktheblak/ruuvitag-gollector/pkg/dewpoint"
+	"github.com/niktheblak/ruuvitag-gollector/pkg/temperature"
+)
+
+// DewPoint calculates dew point from the given temperature and humidity. Returns nil
+// if either of the values is missing or the dew point cannot be calculated.
+func DewPoint(temp, humidity *float64) *float64 {
+	if temp == nil || humidity == nil {
+		return nil
+	}
+	dp, err := dewpoint.Calculate(*temp, temperature.Celsius, *humidity)
+	if err != nil {
+		return nil
+	}
+	return &dp
+}
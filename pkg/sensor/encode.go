@@ -0,0 +1,38 @@
+package sensor
+
+import (
+	"fmt"
+	"math"
+	"net"
+)
+
+// Encode encodes sensor data into RuuviTag manufacturer data using the given data format
+func Encode(format uint8, sd Data) ([]byte, error) {
+	switch format {
+	case 3:
+		return EncodeSensorFormat3(sd)
+	case 5:
+		return EncodeSensorFormat5(sd)
+	default:
+		return nil, fmt.Errorf("unsupported sensor format for encoding: %v", format)
+	}
+}
+
+func encodeAcceleration(a *int, missing int16) int16 {
+	if a == nil {
+		return missing
+	}
+	return int16(clamp(float64(*a), math.MinInt16+1, math.MaxInt16))
+}
+
+func encodeMAC(addr string) []byte {
+	mac, err := net.ParseMAC(addr)
+	if err != nil || len(mac) != 6 {
+		return make([]byte, 6)
+	}
+	return mac
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
@@ -0,0 +1,119 @@
+package sensor
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeRAWv2(t *testing.T) {
+	sd, err := Parse(testData)
+	require.NoError(t, err)
+	sd.Addr = "f7:fa:74:4a:1e:1a"
+	data, err := Encode(5, sd)
+	require.NoError(t, err)
+	assert.Equal(t, testData[:26], data)
+	decoded, err := Parse(data)
+	require.NoError(t, err)
+	sd.Addr = ""
+	assert.Equal(t, sd, decoded)
+}
+
+func TestEncodeRAWv2MissingValues(t *testing.T) {
+	data, err := Encode(5, Data{})
+	require.NoError(t, err)
+	sd, err := Parse(data)
+	require.NoError(t, err)
+	assert.Nil(t, sd.Temperature)
+	assert.Nil(t, sd.Humidity)
+	assert.Nil(t, sd.Pressure)
+	assert.Nil(t, sd.AccelerationX)
+	assert.Nil(t, sd.MeasurementNumber)
+	assert.Equal(t, 0.0, sd.BatteryVoltage)
+	assert.Equal(t, 0, sd.TxPower)
+}
+
+func TestEncodeRAWv1(t *testing.T) {
+	sd := Data{
+		Temperature:    Float64(-12.34),
+		Humidity:       Float64(45.5),
+		Pressure:       Float64(1002.5),
+		AccelerationX:  Int(-1000),
+		AccelerationY:  Int(12),
+		AccelerationZ:  Int(1016),
+		BatteryVoltage: 2.899,
+	}
+	data, err := Encode(3, sd)
+	require.NoError(t, err)
+	decoded, err := Parse(data)
+	require.NoError(t, err)
+	assert.InDelta(t, -12.34, *decoded.Temperature, 0.001)
+	assert.Equal(t, Float64(45.5), decoded.Humidity)
+	assert.Equal(t, Float64(1002.5), decoded.Pressure)
+	assert.Equal(t, Int(-1000), decoded.AccelerationX)
+	assert.Equal(t, Int(12), decoded.AccelerationY)
+	assert.Equal(t, Int(1016), decoded.AccelerationZ)
+	assert.Equal(t, 2.899, decoded.BatteryVoltage)
+}
+
+func TestEncodeRAWv1MissingValues(t *testing.T) {
+	_, err := Encode(3, Data{Humidity: Float64(50), Pressure: Float64(1000)})
+	assert.Error(t, err)
+}
+
+func TestEncodeUnsupportedFormat(t *testing.T) {
+	_, err := Encode(8, Data{})
+	assert.Error(t, err)
+}
+
+// rawv2Data generates random sensor data within the value ranges of data format 5
+type rawv2Data Data
+
+func (rawv2Data) Generate(r *rand.Rand, size int) reflect.Value {
+	sd := rawv2Data{
+		Temperature:       Float64(float64(r.Intn(65535)-32767) * 0.005),
+		Humidity:          Float64(float64(r.Intn(65535)) / 400.0),
+		Pressure:          Float64(float64(r.Intn(65535)+50000) / 100.0),
+		AccelerationX:     Int(r.Intn(65535) - 32767),
+		AccelerationY:     Int(r.Intn(65535) - 32767),
+		AccelerationZ:     Int(r.Intn(65535) - 32767),
+		BatteryVoltage:    float64(r.Intn(2047))/1000.0 + 1.6,
+		TxPower:           r.Intn(31) - 40,
+		MovementCounter:   r.Intn(255),
+		MeasurementNumber: Int(r.Intn(65535)),
+	}
+	return reflect.ValueOf(sd)
+}
+
+func TestEncodeRAWv2RoundTrip(t *testing.T) {
+	roundTrip := func(in rawv2Data) bool {
+		data, err := Encode(5, Data(in))
+		if err != nil {
+			return false
+		}
+		out, err := Parse(data)
+		if err != nil {
+			return false
+		}
+		return closeTo(*in.Temperature, *out.Temperature, 0.0025) &&
+			closeTo(*in.Humidity, *out.Humidity, 0.00125) &&
+			closeTo(*in.Pressure, *out.Pressure, 0.005) &&
+			*in.AccelerationX == *out.AccelerationX &&
+			*in.AccelerationY == *out.AccelerationY &&
+			*in.AccelerationZ == *out.AccelerationZ &&
+			closeTo(in.BatteryVoltage, out.BatteryVoltage, 0.0005) &&
+			(in.TxPower == out.TxPower || in.TxPower == 0) &&
+			in.MovementCounter == out.MovementCounter &&
+			*in.MeasurementNumber == *out.MeasurementNumber
+	}
+	require.NoError(t, quick.Check(roundTrip, nil))
+}
+
+func closeTo(a, b, delta float64) bool {
+	d := a - b
+	return d <= delta && d >= -delta
+}
@@ -0,0 +1,93 @@
+package sensor
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrNoKey is returned when encrypted data is read from a sensor without a configured decryption key
+var ErrNoKey = errors.New("no decryption key configured")
+
+/* Payload:
+Byte    Value Range			Explanation
+---------------------------------------
+0 		08 					Format type code
+1–16 	-					AES-128-ECB encrypted payload, see below
+17		0 — 255				CRC8 (polynomial 0x07, initial value 0x00) of the decrypted payload
+18–23 	00:00:00:...		MAC address
+
+Decrypted payload:
+0–1 	-40.000 — 84.995 	Temperature (16bit signed in .005 centigrade)
+2–3 	0 — 100				Humidity: 16bit unsigned; in .0025%
+4–5 	300 — 11,000 		Atmospheric pressure (16bit unsigned, in Pa with -50000 offset)
+6–7		-					Battery voltage (11 bits) and TX power (5 bits) as in data format 5
+8–9		0 — 65,534 			Movement counter (16bit unsigned)
+10–11 	0 — 65,534 			Measurement sequence number (16bit unsigned)
+12–15	-					Reserved
+*/
+type DataFormat8 struct {
+	Temperature       int16
+	Humidity          uint16
+	Pressure          uint16
+	Power             uint16
+	MovementCounter   uint16
+	MeasurementNumber uint16
+	Reserved          uint32
+}
+
+// ParseSensorFormat8 decrypts and parses data format 8 using the given AES-128 key
+func ParseSensorFormat8(data []byte, key []byte) (sd Data, err error) {
+	if len(key) == 0 {
+		err = ErrNoKey
+		return
+	}
+	if len(data) < 20 {
+		err = fmt.Errorf("invalid data length for sensor format 8: %d", len(data))
+		return
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	plaintext := make([]byte, aes.BlockSize)
+	block.Decrypt(plaintext, data[3:3+aes.BlockSize])
+	crc := data[3+aes.BlockSize]
+	if CRC8(plaintext) != crc {
+		err = fmt.Errorf("CRC mismatch in sensor format 8, possibly wrong decryption key")
+		return
+	}
+	var result DataFormat8
+	err = binary.Read(bytes.NewReader(plaintext), binary.BigEndian, &result)
+	if err != nil {
+		return
+	}
+	sd.Temperature = parseTemperature(result.Temperature)
+	sd.Humidity = parseHumidity(result.Humidity)
+	sd.Pressure = parsePressure(result.Pressure)
+	sd.BatteryVoltage, sd.TxPower = parsePower(result.Power)
+	sd.MovementCounter = int(result.MovementCounter)
+	// The 16-bit counter wraps from 65534 to 0, 65535 is invalid
+	sd.MovementModulus = 65535
+	sd.MeasurementNumber = parseMeasurementNumber(result.MeasurementNumber)
+	sd.SequenceModulus = 65535
+	return
+}
+
+// CRC8 calculates CRC-8 with polynomial 0x07 and initial value 0x00
+func CRC8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
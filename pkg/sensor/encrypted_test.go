@@ -0,0 +1,68 @@
+package sensor
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = []byte{
+	0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77,
+	0x88, 0x99, 0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0xFF,
+}
+
+func encryptFormat8(t *testing.T, key []byte, payload DataFormat8) []byte {
+	buf := new(bytes.Buffer)
+	require.NoError(t, binary.Write(buf, binary.BigEndian, payload))
+	plaintext := buf.Bytes()
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	ciphertext := make([]byte, aes.BlockSize)
+	block.Encrypt(ciphertext, plaintext)
+	data := []byte{0x99, 0x04, 0x08}
+	data = append(data, ciphertext...)
+	data = append(data, CRC8(plaintext))
+	data = append(data, 0xCC, 0xCA, 0x7E, 0x52, 0xCC, 0x34)
+	return data
+}
+
+func TestParseFormat8(t *testing.T) {
+	data := encryptFormat8(t, testKey, DataFormat8{
+		Temperature:       4820,
+		Humidity:          20000,
+		Pressure:          49984,
+		Power:             (1155 << 5) | 11,
+		MovementCounter:   300,
+		MeasurementNumber: 44526,
+	})
+	sd, err := ParseWithKey(data, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, Float64(24.1), sd.Temperature)
+	assert.Equal(t, Float64(50.0), sd.Humidity)
+	assert.Equal(t, Float64(999.84), sd.Pressure)
+	assert.Equal(t, 2.755, sd.BatteryVoltage)
+	assert.Equal(t, -29, sd.TxPower)
+	assert.Equal(t, 300, sd.MovementCounter)
+	assert.Equal(t, Int(44526), sd.MeasurementNumber)
+}
+
+func TestParseFormat8WithoutKey(t *testing.T) {
+	data := encryptFormat8(t, testKey, DataFormat8{Temperature: 4820})
+	_, err := Parse(data)
+	assert.ErrorIs(t, err, ErrNoKey)
+}
+
+func TestParseFormat8WithWrongKey(t *testing.T) {
+	data := encryptFormat8(t, testKey, DataFormat8{Temperature: 4820})
+	wrongKey := make([]byte, 16)
+	_, err := ParseWithKey(data, wrongKey)
+	assert.Error(t, err)
+}
+
+func TestCRC8(t *testing.T) {
+	assert.Equal(t, byte(0xF4), CRC8([]byte("123456789")))
+}
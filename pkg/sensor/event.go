@@ -0,0 +1,33 @@
+package sensor
+
+import (
+	"time"
+)
+
+// Event types
+const (
+	// EventMotion is emitted when the movement counter of a sensor increases
+	EventMotion = "motion"
+	// EventLinkQuality is emitted periodically with the reception statistics of a sensor
+	EventLinkQuality = "link_quality"
+	// EventLowBattery is emitted once when the battery level of a sensor falls below the threshold
+	EventLowBattery = "low_battery"
+	// EventAlert is emitted when an alerting rule fires
+	EventAlert = "alert"
+	// EventAlertResolved is emitted when a fired alerting rule resolves
+	EventAlertResolved = "alert_resolved"
+	// EventOffline is emitted when no measurements have been received from a sensor within the timeout
+	EventOffline = "offline"
+	// EventOnline is emitted when measurements are received again from an offline sensor
+	EventOnline = "online"
+)
+
+// Event is an occurrence detected from the measurements of a sensor. Events are
+// exported separately from measurements.
+type Event struct {
+	Type      string                 `json:"type"`
+	Addr      string                 `json:"mac"`
+	Name      string                 `json:"name"`
+	Timestamp time.Time              `json:"ts"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
@@ -0,0 +1,95 @@
+package sensor
+
+import (
+	"reflect"
+	"strings"
+)
+
+// numericFields contains the indexes of the numeric fields of Data by JSON name
+var numericFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(Data{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Float64, reflect.Int, reflect.Bool:
+			fields[name] = i
+		}
+	}
+	return fields
+}()
+
+// FieldNames returns the JSON names of the numeric fields of Data
+func FieldNames() []string {
+	var names []string
+	t := reflect.TypeOf(Data{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if _, ok := numericFields[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsField reports whether name is the JSON name of a numeric field of Data
+func IsField(name string) bool {
+	_, ok := numericFields[name]
+	return ok
+}
+
+// Value returns the value of the numeric field with the given JSON name, such as "temperature".
+// Boolean fields are returned as 0 or 1. The value is not ok if the field does not exist
+// or its value is missing. Zero values of the non-pointer fields, such as battery_voltage,
+// are missing, and movement_counter is missing if the data format has no movement counter.
+func (sd Data) Value(field string) (float64, bool) {
+	i, ok := numericFields[field]
+	if !ok {
+		return 0, false
+	}
+	v := reflect.ValueOf(sd).Field(i)
+	switch {
+	case field == "movement_counter":
+		if sd.MovementModulus == 0 {
+			return 0, false
+		}
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	case v.IsZero():
+		return 0, false
+	}
+	switch v.Kind() {
+	case reflect.Float64:
+		return v.Float(), true
+	case reflect.Int:
+		return float64(v.Int()), true
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Values returns the values of all numeric fields that have a value by JSON name
+func (sd Data) Values() map[string]float64 {
+	values := make(map[string]float64)
+	for name := range numericFields {
+		if v, ok := sd.Value(name); ok {
+			values[name] = v
+		}
+	}
+	return values
+}
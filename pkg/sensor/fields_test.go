@@ -0,0 +1,55 @@
+package sensor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue(t *testing.T) {
+	sd := Data{
+		Addr:               "cc:ca:7e:52:cc:34",
+		Temperature:        Float64(21.5),
+		BatteryVoltage:     2.9,
+		AccelerationX:      Int(-12),
+		MovementCounter:    4,
+		MovementModulus:    255,
+		OrientationChanged: Bool(true),
+	}
+	v, ok := sd.Value("temperature")
+	assert.True(t, ok)
+	assert.Equal(t, 21.5, v)
+	v, ok = sd.Value("acceleration_x")
+	assert.True(t, ok)
+	assert.Equal(t, -12.0, v)
+	v, ok = sd.Value("orientation_changed")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, v)
+	_, ok = sd.Value("humidity")
+	assert.False(t, ok)
+	_, ok = sd.Value("mac")
+	assert.False(t, ok)
+	_, ok = sd.Value("nonexistent")
+	assert.False(t, ok)
+	assert.Equal(t, map[string]float64{
+		"temperature":         21.5,
+		"battery_voltage":     2.9,
+		"acceleration_x":      -12,
+		"movement_counter":    4,
+		"orientation_changed": 1,
+	}, sd.Values())
+	// Zero values of non-pointer fields are missing
+	_, ok = sd.Value("tx_power")
+	assert.False(t, ok)
+	sd.MovementCounter = 0
+	v, ok = sd.Value("movement_counter")
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, v)
+	sd.MovementModulus = 0
+	_, ok = sd.Value("movement_counter")
+	assert.False(t, ok)
+	assert.Contains(t, FieldNames(), "pressure")
+	assert.NotContains(t, FieldNames(), "ts")
+	assert.True(t, IsField("temperature"))
+	assert.False(t, IsField("mac"))
+}
@@ -0,0 +1,88 @@
+package sensor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+/* Payload:
+Byte    Value Range			Explanation
+---------------------------------------
+0 		06 					Format type code
+1–2 	-40.000 — 84.995 	Temperature (16bit signed in .005 centigrade)
+3–4 	0 — 100				Humidity: 16bit unsigned; in .0025%
+5–6 	300 — 11,000 		Atmospheric pressure (16bit unsigned, in Pa with -50000 offset)
+7–8 	0 — 1000			PM2.5 (16bit unsigned, in 0.1 µg/m³). 0xFFFF indicates invalid.
+9–10 	0 — 40,000			CO2 concentration in ppm (16bit unsigned). 0xFFFF indicates invalid.
+11		0 — 500				VOC index, bits 9..2 (LSB is in flags byte)
+12		0 — 500				NOx index, bits 9..2 (LSB is in flags byte). Index 511 indicates invalid.
+13		0 — 65,535			Luminosity in lux (logarithmic encoding). 0xFF indicates invalid.
+14		-					Reserved
+15		0 — 255				Measurement sequence number (8bit unsigned)
+16		-					Flags. Bit 6 is the VOC index LSB, bit 7 is the NOx index LSB.
+17–19 	00:00:00			Lowest 24 bits of the MAC address
+*/
+type DataFormat6 struct {
+	ManufacturerID    uint16
+	DataFormat        uint8
+	Temperature       int16
+	Humidity          uint16
+	Pressure          uint16
+	PM25              uint16
+	CO2               uint16
+	VOC               uint8
+	NOX               uint8
+	Luminosity        uint8
+	Reserved          uint8
+	MeasurementNumber uint8
+	Flags             uint8
+}
+
+func ParseSensorFormat6(data []byte) (sd Data, err error) {
+	reader := bytes.NewReader(data)
+	var result DataFormat6
+	err = binary.Read(reader, binary.BigEndian, &result)
+	if err != nil {
+		return
+	}
+	sd.Temperature = parseTemperature(result.Temperature)
+	sd.Humidity = parseHumidity(result.Humidity)
+	sd.Pressure = parsePressure(result.Pressure)
+	sd.PM25 = parsePM(result.PM25)
+	sd.CO2 = parseCO2(result.CO2)
+	sd.VOCIndex = parseIndex(result.VOC, result.Flags&(1<<6) != 0)
+	sd.NOXIndex = parseIndex(result.NOX, result.Flags&(1<<7) != 0)
+	if result.Luminosity != 0xFF {
+		// Luminosity is encoded logarithmically over the range 0 — 65535 lux
+		sd.Luminosity = Float64(math.Exp(float64(result.Luminosity)*math.Log(65536)/254) - 1)
+	}
+	sd.MeasurementNumber = Int(int(result.MeasurementNumber))
+	sd.SequenceModulus = 256
+	return
+}
+
+func parsePM(pm uint16) *float64 {
+	if pm == 0xFFFF {
+		return nil
+	}
+	return Float64(float64(pm) / 10.0)
+}
+
+func parseCO2(co2 uint16) *int {
+	if co2 == 0xFFFF {
+		return nil
+	}
+	return Int(int(co2))
+}
+
+func parseIndex(msb uint8, lsb bool) *int {
+	v := int(msb) << 1
+	if lsb {
+		v |= 1
+	}
+	if v == 0x1FF {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,45 @@
+package sensor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat6(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.BigEndian, DataFormat6{
+		ManufacturerID:    0x9904,
+		DataFormat:        6,
+		Temperature:       4820,
+		Humidity:          20000,
+		Pressure:          49984,
+		PM25:              115,
+		CO2:               801,
+		VOC:               50,
+		NOX:               0xFF,
+		Luminosity:        0,
+		MeasurementNumber: 17,
+		Flags:             1<<6 | 1<<7,
+	})
+	require.NoError(t, err)
+	data, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, Float64(24.1), data.Temperature)
+	assert.Equal(t, Float64(50.0), data.Humidity)
+	assert.Equal(t, Float64(999.84), data.Pressure)
+	require.NotNil(t, data.PM25)
+	assert.Equal(t, 11.5, *data.PM25)
+	require.NotNil(t, data.CO2)
+	assert.Equal(t, 801, *data.CO2)
+	require.NotNil(t, data.VOCIndex)
+	assert.Equal(t, 101, *data.VOCIndex)
+	assert.Nil(t, data.NOXIndex, "NOx index 511 is invalid")
+	require.NotNil(t, data.Luminosity)
+	assert.Equal(t, 0.0, *data.Luminosity)
+	assert.Nil(t, data.PM1)
+	assert.Equal(t, Int(17), data.MeasurementNumber)
+}
@@ -0,0 +1,77 @@
+package sensor
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+/* Payload:
+Byte    Value Range			Explanation
+---------------------------------------
+0 		E1 					Format type code
+1–2 	-40.000 — 84.995 	Temperature (16bit signed in .005 centigrade)
+3–4 	0 — 100				Humidity: 16bit unsigned; in .0025%
+5–6 	300 — 11,000 		Atmospheric pressure (16bit unsigned, in Pa with -50000 offset)
+7–8 	0 — 1000			PM1.0 (16bit unsigned, in 0.1 µg/m³). 0xFFFF indicates invalid.
+9–10 	0 — 1000			PM2.5 (16bit unsigned, in 0.1 µg/m³). 0xFFFF indicates invalid.
+11–12 	0 — 1000			PM4.0 (16bit unsigned, in 0.1 µg/m³). 0xFFFF indicates invalid.
+13–14 	0 — 1000			PM10.0 (16bit unsigned, in 0.1 µg/m³). 0xFFFF indicates invalid.
+15–16 	0 — 40,000			CO2 concentration in ppm (16bit unsigned). 0xFFFF indicates invalid.
+17		0 — 500				VOC index, bits 9..2 (LSB is in flags byte)
+18		0 — 500				NOx index, bits 9..2 (LSB is in flags byte). Index 511 indicates invalid.
+19–21	0 — 144,284			Luminosity (24bit unsigned, in 0.01 lux). 0xFFFFFF indicates invalid.
+22–24	-					Reserved
+25–27	0 — 16,777,214		Measurement sequence number (24bit unsigned)
+28		-					Flags. Bit 6 is the VOC index LSB, bit 7 is the NOx index LSB.
+29–33	-					Reserved
+34–39 	00:00:00:...		MAC address
+*/
+type DataFormatE1 struct {
+	ManufacturerID    uint16
+	DataFormat        uint8
+	Temperature       int16
+	Humidity          uint16
+	Pressure          uint16
+	PM1               uint16
+	PM25              uint16
+	PM4               uint16
+	PM10              uint16
+	CO2               uint16
+	VOC               uint8
+	NOX               uint8
+	Luminosity        [3]uint8
+	Reserved          [3]uint8
+	MeasurementNumber [3]uint8
+	Flags             uint8
+}
+
+func ParseSensorFormatE1(data []byte) (sd Data, err error) {
+	reader := bytes.NewReader(data)
+	var result DataFormatE1
+	err = binary.Read(reader, binary.BigEndian, &result)
+	if err != nil {
+		return
+	}
+	sd.Temperature = parseTemperature(result.Temperature)
+	sd.Humidity = parseHumidity(result.Humidity)
+	sd.Pressure = parsePressure(result.Pressure)
+	sd.PM1 = parsePM(result.PM1)
+	sd.PM25 = parsePM(result.PM25)
+	sd.PM4 = parsePM(result.PM4)
+	sd.PM10 = parsePM(result.PM10)
+	sd.CO2 = parseCO2(result.CO2)
+	sd.VOCIndex = parseIndex(result.VOC, result.Flags&(1<<6) != 0)
+	sd.NOXIndex = parseIndex(result.NOX, result.Flags&(1<<7) != 0)
+	if lum := uint24(result.Luminosity); lum != 0xFFFFFF {
+		sd.Luminosity = Float64(float64(lum) / 100.0)
+	}
+	if n := uint24(result.MeasurementNumber); n != 0xFFFFFF {
+		sd.MeasurementNumber = Int(n)
+	}
+	sd.SequenceModulus = 0xFFFFFF
+	return
+}
+
+func uint24(b [3]uint8) int {
+	return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+}
@@ -0,0 +1,54 @@
+package sensor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormatE1(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.BigEndian, DataFormatE1{
+		ManufacturerID:    0x9904,
+		DataFormat:        0xE1,
+		Temperature:       4820,
+		Humidity:          20000,
+		Pressure:          49984,
+		PM1:               29,
+		PM25:              115,
+		PM4:               0xFFFF,
+		PM10:              211,
+		CO2:               801,
+		VOC:               50,
+		NOX:               1,
+		Luminosity:        [3]uint8{0x00, 0x30, 0x39},
+		MeasurementNumber: [3]uint8{0x01, 0x00, 0x00},
+	})
+	require.NoError(t, err)
+	// Trailing reserved bytes and MAC address
+	buf.Write(make([]byte, 11))
+	data, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, Float64(24.1), data.Temperature)
+	assert.Equal(t, Float64(50.0), data.Humidity)
+	assert.Equal(t, Float64(999.84), data.Pressure)
+	require.NotNil(t, data.PM1)
+	assert.Equal(t, 2.9, *data.PM1)
+	require.NotNil(t, data.PM25)
+	assert.Equal(t, 11.5, *data.PM25)
+	assert.Nil(t, data.PM4)
+	require.NotNil(t, data.PM10)
+	assert.Equal(t, 21.1, *data.PM10)
+	require.NotNil(t, data.CO2)
+	assert.Equal(t, 801, *data.CO2)
+	require.NotNil(t, data.VOCIndex)
+	assert.Equal(t, 100, *data.VOCIndex)
+	require.NotNil(t, data.NOXIndex)
+	assert.Equal(t, 2, *data.NOXIndex)
+	require.NotNil(t, data.Luminosity)
+	assert.Equal(t, 123.45, *data.Luminosity)
+	assert.Equal(t, Int(65536), data.MeasurementNumber)
+}
@@ -0,0 +1,49 @@
+package sensor
+
+import (
+	"fmt"
+)
+
+// GoveeManufacturerID is the manufacturer ID used by Govee H5075 thermometers
+const GoveeManufacturerID = 0xEC88
+
+/* Payload:
+Byte    Explanation
+---------------------------------------
+0–1 	Manufacturer ID (0x88 0xEC)
+2		-
+3–5		Temperature and humidity (24bit unsigned, big endian). The value is
+		temperature * 10000 + humidity * 10 with the MSB indicating negative temperature.
+6		Battery level (8bit unsigned, in %)
+*/
+
+// Govee decodes manufacturer data broadcast by Govee H5075 thermometers
+type Govee struct {
+}
+
+func (d Govee) Name() string {
+	return "Govee H5075"
+}
+
+func (d Govee) Match() Match {
+	return Match{ManufacturerID: GoveeManufacturerID}
+}
+
+func (d Govee) Decode(adv Advertisement) (sd Data, err error) {
+	data := adv.ManufacturerData
+	if len(data) < 7 {
+		err = fmt.Errorf("invalid Govee data length: %d", len(data))
+		return
+	}
+	value := int(data[3])<<16 | int(data[4])<<8 | int(data[5])
+	negative := value&0x800000 != 0
+	value &^= 0x800000
+	temp := float64(value/1000) / 10.0
+	if negative {
+		temp = -temp
+	}
+	sd.Temperature = Float64(temp)
+	sd.Humidity = Float64(float64(value%1000) / 10.0)
+	sd.BatteryLevel = Float64(float64(data[6]))
+	return
+}
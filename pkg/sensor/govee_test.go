@@ -0,0 +1,23 @@
+package sensor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeGovee(t *testing.T) {
+	sd, err := Govee{}.Decode(Advertisement{ManufacturerData: []byte{0x88, 0xEC, 0x00, 0x03, 0x6D, 0x2D, 0x64, 0x00}})
+	require.NoError(t, err)
+	assert.Equal(t, Float64(22.4), sd.Temperature)
+	assert.Equal(t, Float64(55.7), sd.Humidity)
+	assert.Equal(t, Float64(100), sd.BatteryLevel)
+}
+
+func TestDecodeGoveeNegative(t *testing.T) {
+	sd, err := Govee{}.Decode(Advertisement{ManufacturerData: []byte{0x88, 0xEC, 0x00, 0x80, 0xC7, 0x4C, 0x64, 0x00}})
+	require.NoError(t, err)
+	assert.Equal(t, Float64(-5.1), sd.Temperature)
+	assert.Equal(t, Float64(2.0), sd.Humidity)
+}
@@ -6,6 +6,11 @@ import (
 )
 
 func Parse(data []byte) (sensorData Data, err error) {
+	return ParseWithKey(data, nil)
+}
+
+// ParseWithKey parses RuuviTag data using the given key to decrypt encrypted data formats
+func ParseWithKey(data []byte, key []byte) (sensorData Data, err error) {
 	if !IsRuuviTag(data) {
 		err = fmt.Errorf("not a RuuviTag device")
 		return
@@ -18,6 +23,15 @@ func Parse(data []byte) (sensorData Data, err error) {
 	case 5:
 		sensorData, err = ParseSensorFormat5(data)
 		return
+	case 6:
+		sensorData, err = ParseSensorFormat6(data)
+		return
+	case 8:
+		sensorData, err = ParseSensorFormat8(data, key)
+		return
+	case 0xE1:
+		sensorData, err = ParseSensorFormatE1(data)
+		return
 	default:
 		err = fmt.Errorf("unknown sensor format: %v", sensorFormat)
 		return
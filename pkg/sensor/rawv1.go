@@ -3,9 +3,8 @@ package sensor
 import (
 	"bytes"
 	"encoding/binary"
-
-	"github.com/niktheblak/ruuvitag-gollector/pkg/dewpoint"
-	"github.com/niktheblak/ruuvitag-gollector/pkg/temperature"
+	"fmt"
+	"math"
 )
 
 type DataFormat3 struct {
@@ -39,13 +38,43 @@ func ParseSensorFormat3(data []byte) (sd Data, err error) {
 	if err != nil {
 		return
 	}
-	sd.Temperature = ParseTemperature(result.Temperature, result.TemperatureFraction)
-	sd.Humidity = float64(result.Humidity) / 2.0
-	sd.DewPoint, _ = dewpoint.Calculate(sd.Temperature, temperature.Celsius, sd.Humidity)
-	sd.Pressure = float64(int(result.Pressure)+50000) / 100.0
-	sd.BatteryVoltage = float64(result.BatteryVoltageMv)
-	sd.AccelerationX = int(result.AccelerationX)
-	sd.AccelerationY = int(result.AccelerationY)
-	sd.AccelerationZ = int(result.AccelerationZ)
+	sd.Temperature = Float64(ParseTemperature(result.Temperature, result.TemperatureFraction))
+	sd.Humidity = Float64(float64(result.Humidity) / 2.0)
+	sd.DewPoint = DewPoint(sd.Temperature, sd.Humidity)
+	sd.Pressure = Float64(float64(int(result.Pressure)+50000) / 100.0)
+	sd.BatteryVoltage = float64(result.BatteryVoltageMv) / 1000.0
+	sd.AccelerationX = Int(int(result.AccelerationX))
+	sd.AccelerationY = Int(int(result.AccelerationY))
+	sd.AccelerationZ = Int(int(result.AccelerationZ))
 	return
 }
+
+// EncodeSensorFormat3 encodes sensor data into data format 3 (RAWv1). Format 3 has
+// no representation for missing temperature, humidity or pressure values.
+func EncodeSensorFormat3(sd Data) ([]byte, error) {
+	if sd.Temperature == nil || sd.Humidity == nil || sd.Pressure == nil {
+		return nil, fmt.Errorf("sensor format 3 requires temperature, humidity and pressure")
+	}
+	temp := math.Abs(*sd.Temperature)
+	whole := math.Floor(temp)
+	result := DataFormat3{
+		ManufacturerID:      0x9904,
+		DataFormat:          3,
+		Humidity:            uint8(clamp(math.Round(*sd.Humidity*2), 0, 255)),
+		Temperature:         uint8(clamp(whole, 0, 127)),
+		TemperatureFraction: uint8(clamp(math.Round((temp-whole)*100), 0, 99)),
+		Pressure:            uint16(clamp(math.Round(*sd.Pressure*100-50000), 0, 65535)),
+		AccelerationX:       encodeAcceleration(sd.AccelerationX, 0),
+		AccelerationY:       encodeAcceleration(sd.AccelerationY, 0),
+		AccelerationZ:       encodeAcceleration(sd.AccelerationZ, 0),
+		BatteryVoltageMv:    uint16(clamp(math.Round(sd.BatteryVoltage*1000), 0, 65535)),
+	}
+	if *sd.Temperature < 0 && result.Temperature|result.TemperatureFraction != 0 {
+		result.Temperature |= 1 << 7
+	}
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.BigEndian, result); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseTemperature(t *testing.T) {
@@ -13,3 +14,16 @@ func TestParseTemperature(t *testing.T) {
 	assert.Equal(t, 2.2, ParseTemperature(2, 20), "fraction is ok")
 	assert.Equal(t, -2.99, ParseTemperature(130, 99), "fraction is ok negative")
 }
+
+func TestParseRAWv1(t *testing.T) {
+	data := []byte{0x99, 0x04, 0x03, 0x29, 0x1A, 0x1E, 0xCE, 0x1E, 0xFC, 0x18, 0xF9, 0x42, 0x02, 0xCA, 0x0B, 0x53}
+	sd, err := ParseSensorFormat3(data)
+	require.NoError(t, err)
+	assert.Equal(t, Float64(20.5), sd.Humidity)
+	assert.Equal(t, Float64(26.3), sd.Temperature)
+	assert.Equal(t, Float64(1027.66), sd.Pressure)
+	assert.Equal(t, Int(-1000), sd.AccelerationX)
+	assert.Equal(t, Int(-1726), sd.AccelerationY)
+	assert.Equal(t, Int(714), sd.AccelerationZ)
+	assert.Equal(t, 2.899, sd.BatteryVoltage)
+}
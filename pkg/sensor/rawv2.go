@@ -3,6 +3,7 @@ package sensor
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
 )
 
 /* Payload:
@@ -55,21 +56,112 @@ func ParseSensorFormat5(data []byte) (sd Data, err error) {
 	if err != nil {
 		return
 	}
-	sd.Temperature = float64(result.Temperature) * 0.005
-	sd.Humidity = float64(result.Humidity) / 400.0
-	sd.Pressure = float64(int(result.Pressure)+50000) / 100.0
-	sd.AccelerationX = int(result.AccelerationX)
-	sd.AccelerationY = int(result.AccelerationY)
-	sd.AccelerationZ = int(result.AccelerationZ)
-	batteryVoltage := int(result.Power >> 5)
-	if batteryVoltage != 2047 {
-		sd.BatteryVoltage = float64(batteryVoltage)/1000.0 + 1.6
+	sd.Temperature = parseTemperature(result.Temperature)
+	sd.Humidity = parseHumidity(result.Humidity)
+	sd.Pressure = parsePressure(result.Pressure)
+	sd.AccelerationX = parseAcceleration(result.AccelerationX)
+	sd.AccelerationY = parseAcceleration(result.AccelerationY)
+	sd.AccelerationZ = parseAcceleration(result.AccelerationZ)
+	sd.BatteryVoltage, sd.TxPower = parsePower(result.Power)
+	sd.MovementCounter = int(result.MovementCounter)
+	// The 8-bit counter wraps from 254 to 0, 255 is invalid
+	sd.MovementModulus = 255
+	sd.MeasurementNumber = parseMeasurementNumber(result.MeasurementNumber)
+	sd.SequenceModulus = 65535
+	return
+}
+
+func parseTemperature(t int16) *float64 {
+	if t == math.MinInt16 {
+		return nil
 	}
-	txPower := int(result.Power & 0x1F)
-	if txPower != 0x1F {
-		sd.TxPower = txPower - 40
+	return Float64(float64(t) * 0.005)
+}
+
+func parseHumidity(h uint16) *float64 {
+	if h == 0xFFFF {
+		return nil
+	}
+	return Float64(float64(h) / 400.0)
+}
+
+func parsePressure(p uint16) *float64 {
+	if p == 0xFFFF {
+		return nil
+	}
+	return Float64(float64(int(p)+50000) / 100.0)
+}
+
+func parseAcceleration(a int16) *int {
+	if a == math.MinInt16 {
+		return nil
+	}
+	return Int(int(a))
+}
+
+func parseMeasurementNumber(n uint16) *int {
+	if n == 0xFFFF {
+		return nil
+	}
+	return Int(int(n))
+}
+
+func parsePower(power uint16) (batteryVoltage float64, txPower int) {
+	bv := int(power >> 5)
+	if bv != 2047 {
+		batteryVoltage = float64(bv)/1000.0 + 1.6
+	}
+	tx := int(power & 0x1F)
+	if tx != 0x1F {
+		txPower = tx - 40
 	}
-	sd.MovementCounter = int(result.MovementCounter)
-	sd.MeasurementNumber = int(result.MeasurementNumber)
 	return
 }
+
+// EncodeSensorFormat5 encodes sensor data into data format 5 (RAWv2). Missing values
+// are encoded as the invalid value of the respective field.
+func EncodeSensorFormat5(sd Data) ([]byte, error) {
+	result := DataFormat5{
+		ManufacturerID:    0x9904,
+		DataFormat:        5,
+		Temperature:       math.MinInt16,
+		Humidity:          0xFFFF,
+		Pressure:          0xFFFF,
+		AccelerationX:     encodeAcceleration(sd.AccelerationX, math.MinInt16),
+		AccelerationY:     encodeAcceleration(sd.AccelerationY, math.MinInt16),
+		AccelerationZ:     encodeAcceleration(sd.AccelerationZ, math.MinInt16),
+		Power:             encodePower(sd.BatteryVoltage, sd.TxPower),
+		MovementCounter:   uint8(clamp(float64(sd.MovementCounter), 0, 254)),
+		MeasurementNumber: 0xFFFF,
+	}
+	if sd.Temperature != nil {
+		result.Temperature = int16(clamp(math.Round(*sd.Temperature/0.005), math.MinInt16+1, math.MaxInt16))
+	}
+	if sd.Humidity != nil {
+		result.Humidity = uint16(clamp(math.Round(*sd.Humidity*400), 0, 65534))
+	}
+	if sd.Pressure != nil {
+		result.Pressure = uint16(clamp(math.Round(*sd.Pressure*100-50000), 0, 65534))
+	}
+	if sd.MeasurementNumber != nil {
+		result.MeasurementNumber = uint16(clamp(float64(*sd.MeasurementNumber), 0, 65534))
+	}
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.BigEndian, result); err != nil {
+		return nil, err
+	}
+	buf.Write(encodeMAC(sd.Addr))
+	return buf.Bytes(), nil
+}
+
+func encodePower(batteryVoltage float64, txPower int) uint16 {
+	bv := uint16(2047)
+	if batteryVoltage != 0 {
+		bv = uint16(clamp(math.Round((batteryVoltage-1.6)*1000), 0, 2046))
+	}
+	tx := uint16(0x1F)
+	if txPower != 0 {
+		tx = uint16(clamp(float64(txPower+40), 0, 30))
+	}
+	return bv<<5 | tx
+}
@@ -1,6 +1,8 @@
 package sensor
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,14 +22,44 @@ func TestRAWv2Format(t *testing.T) {
 func TestParseRAWv2Data(t *testing.T) {
 	data, err := Parse(testData)
 	require.NoError(t, err)
-	assert.Equal(t, 24.1, data.Temperature)
-	assert.Equal(t, 100.0, data.Humidity)
-	assert.Equal(t, 999.84, data.Pressure)
+	assert.Equal(t, Float64(24.1), data.Temperature)
+	assert.Equal(t, Float64(100.0), data.Humidity)
+	assert.Equal(t, Float64(999.84), data.Pressure)
 	assert.Equal(t, 2.755, data.BatteryVoltage)
 	assert.Equal(t, -18, data.TxPower)
-	assert.Equal(t, 56, data.AccelerationX)
-	assert.Equal(t, 228, data.AccelerationY)
-	assert.Equal(t, 996, data.AccelerationZ)
+	assert.Equal(t, Int(56), data.AccelerationX)
+	assert.Equal(t, Int(228), data.AccelerationY)
+	assert.Equal(t, Int(996), data.AccelerationZ)
 	assert.Equal(t, 65, data.MovementCounter)
-	assert.Equal(t, 44526, data.MeasurementNumber)
+	assert.Equal(t, Int(44526), data.MeasurementNumber)
+}
+
+func TestParseRAWv2InvalidValues(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.BigEndian, DataFormat5{
+		ManufacturerID:    0x9904,
+		DataFormat:        5,
+		Temperature:       -32768,
+		Humidity:          0xFFFF,
+		Pressure:          0xFFFF,
+		AccelerationX:     -32768,
+		AccelerationY:     -32768,
+		AccelerationZ:     -32768,
+		Power:             0xFFFF,
+		MovementCounter:   0,
+		MeasurementNumber: 0xFFFF,
+	})
+	require.NoError(t, err)
+	data, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	assert.Nil(t, data.Temperature)
+	assert.Nil(t, data.Humidity)
+	assert.Nil(t, data.DewPoint)
+	assert.Nil(t, data.Pressure)
+	assert.Nil(t, data.AccelerationX)
+	assert.Nil(t, data.AccelerationY)
+	assert.Nil(t, data.AccelerationZ)
+	assert.Nil(t, data.MeasurementNumber)
+	assert.Equal(t, 0.0, data.BatteryVoltage)
+	assert.Equal(t, 0, data.TxPower)
 }
@@ -0,0 +1,51 @@
+package sensor
+
+import (
+	"fmt"
+)
+
+// SwitchBotServiceUUID is the 16-bit service data UUID used by SwitchBot devices
+const SwitchBotServiceUUID = 0xFD3D
+
+/* Payload:
+Byte    Explanation
+---------------------------------------
+0		Device type (0x54 for Meter, 0x69 for Meter Plus)
+1		Status
+2		Battery level (7 bits, in %)
+3		Temperature decimal part (4 bits, in 0.1 degrees)
+4		Temperature integer part (7 bits). The MSB is set for positive temperatures.
+5		Humidity (7 bits, in %)
+*/
+
+// SwitchBot decodes service data broadcast by SwitchBot Meter thermometers
+type SwitchBot struct {
+}
+
+func (d SwitchBot) Name() string {
+	return "SwitchBot Meter"
+}
+
+func (d SwitchBot) Match() Match {
+	return Match{ServiceUUID: SwitchBotServiceUUID}
+}
+
+func (d SwitchBot) Decode(adv Advertisement) (sd Data, err error) {
+	data := adv.ServiceData[SwitchBotServiceUUID]
+	if len(data) < 6 {
+		err = fmt.Errorf("invalid SwitchBot data length: %d", len(data))
+		return
+	}
+	if data[0]&0x7F != 0x54 && data[0]&0x7F != 0x69 {
+		err = fmt.Errorf("unsupported SwitchBot device type: %#x", data[0])
+		return
+	}
+	temp := float64(data[4]&0x7F) + float64(data[3]&0x0F)/10.0
+	if data[4]&0x80 == 0 {
+		temp = -temp
+	}
+	sd.Temperature = Float64(temp)
+	sd.Humidity = Float64(float64(data[5] & 0x7F))
+	sd.BatteryLevel = Float64(float64(data[2] & 0x7F))
+	return
+}
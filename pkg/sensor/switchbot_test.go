@@ -0,0 +1,20 @@
+package sensor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeSwitchBot(t *testing.T) {
+	sd, err := SwitchBot{}.Decode(Advertisement{ServiceData: map[uint16][]byte{SwitchBotServiceUUID: {0x54, 0x00, 0x64, 0x05, 0x96, 0x2D}}})
+	require.NoError(t, err)
+	assert.Equal(t, Float64(22.5), sd.Temperature)
+	assert.Equal(t, Float64(45.0), sd.Humidity)
+	assert.Equal(t, Float64(100), sd.BatteryLevel)
+
+	sd, err = SwitchBot{}.Decode(Advertisement{ServiceData: map[uint16][]byte{SwitchBotServiceUUID: {0x54, 0x00, 0x64, 0x03, 0x04, 0x2D}}})
+	require.NoError(t, err)
+	assert.Equal(t, Float64(-4.3), sd.Temperature)
+}
@@ -0,0 +1,90 @@
+package weather
+
+import (
+	"math"
+)
+
+// Pressure trends
+const (
+	Rising  = "rising"
+	Falling = "falling"
+	Steady  = "steady"
+)
+
+// SteadyChange is the largest 3-hour pressure change (hPa) considered steady
+const SteadyChange = 1.6
+
+// tolerance is the largest pressure change (hPa) within half of the tendency period
+// considered steady when determining the WMO tendency code
+const tolerance = 0.1
+
+// Trend returns the trend of the 3-hour pressure change (hPa)
+func Trend(change float64) string {
+	switch {
+	case change >= SteadyChange:
+		return Rising
+	case change <= -SteadyChange:
+		return Falling
+	default:
+		return Steady
+	}
+}
+
+// TendencyCode returns the WMO characteristic of pressure tendency (code table 0200) from
+// the pressure 3 hours ago, 1.5 hours ago and now (hPa):
+//
+//	0 Increasing, then decreasing; pressure the same or higher than 3 hours ago
+//	1 Increasing, then steady; or increasing, then increasing more slowly
+//	2 Increasing steadily or unsteadily
+//	3 Decreasing or steady, then increasing; or increasing, then increasing more rapidly
+//	4 Steady; pressure the same as 3 hours ago
+//	5 Decreasing, then increasing; pressure the same or lower than 3 hours ago
+//	6 Decreasing, then steady; or decreasing, then decreasing more slowly
+//	7 Decreasing steadily or unsteadily
+//	8 Steady or increasing, then decreasing; or decreasing, then decreasing more rapidly
+func TendencyCode(p0, p1, p2 float64) int {
+	d1 := p1 - p0
+	d2 := p2 - p1
+	change := p2 - p0
+	switch {
+	case math.Abs(change) <= tolerance:
+		switch {
+		case d1 > tolerance && d2 < -tolerance:
+			return 0
+		case d1 < -tolerance && d2 > tolerance:
+			return 5
+		default:
+			return 4
+		}
+	case change > 0:
+		switch {
+		case d1 > tolerance && d2 < -tolerance:
+			return 0
+		case d1 > tolerance && d2 <= tolerance:
+			return 1
+		case d1 <= tolerance:
+			return 3
+		case d2 < d1/2:
+			return 1
+		case d2 > d1*2:
+			return 3
+		default:
+			return 2
+		}
+	default:
+		switch {
+		case d1 < -tolerance && d2 > tolerance:
+			return 5
+		case d1 < -tolerance && d2 >= -tolerance:
+			return 6
+		case d1 >= -tolerance:
+			return 8
+		case d2 > d1/2:
+			return 6
+		case d2 < d1*2:
+			return 8
+		default:
+			return 7
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package weather
+
+import (
+	"sync"
+	"time"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+// Period is the period of the pressure tendency
+const Period = 3 * time.Hour
+
+// sampleInterval is the minimum time between stored pressure samples
+const sampleInterval = time.Minute
+
+// maxOffset is the largest age of a stored sample relative to the time it is used for
+const maxOffset = 10 * time.Minute
+
+// Station adds the 3-hour pressure tendency and a Zambretti forecast to the measurements
+// of outdoor sensors once it has pressure history over the whole tendency period
+type Station struct {
+	// Addrs contains the addresses of outdoor sensors
+	Addrs map[string]bool
+
+	mu      sync.Mutex
+	history map[string][]sample
+}
+
+type sample struct {
+	ts       time.Time
+	pressure float64
+}
+
+// Process adds the pressure tendency and forecast to measurements of outdoor sensors
+func (s *Station) Process(sd sensor.Data) (sensor.Data, error) {
+	if !s.Addrs[sd.Addr] || sd.Pressure == nil {
+		return sd, nil
+	}
+	now := sd.Timestamp
+	p2 := *sd.Pressure
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.history == nil {
+		s.history = make(map[string][]sample)
+	}
+	h := s.history[sd.Addr]
+	if len(h) == 0 || now.Sub(h[len(h)-1].ts) >= sampleInterval {
+		h = append(h, sample{ts: now, pressure: p2})
+	}
+	for len(h) > 0 && now.Sub(h[0].ts) > Period+maxOffset {
+		h = h[1:]
+	}
+	s.history[sd.Addr] = h
+	p0, ok := at(h, now.Add(-Period))
+	if !ok {
+		return sd, nil
+	}
+	p1, ok := at(h, now.Add(-Period/2))
+	if !ok {
+		return sd, nil
+	}
+	change := p2 - p0
+	trend := Trend(change)
+	sd.PressureTendency = sensor.Float64(change)
+	sd.PressureTendencyCode = sensor.Int(TendencyCode(p0, p1, p2))
+	sd.PressureTrend = trend
+	slp := p2
+	if sd.SeaLevelPressure != nil {
+		slp = *sd.SeaLevelPressure
+	}
+	code, forecast := Zambretti(slp, trend)
+	sd.ForecastCode = sensor.Int(code)
+	sd.Forecast = forecast
+	return sd, nil
+}
+
+// at returns the pressure of the latest sample at or before the given time
+func at(h []sample, ts time.Time) (float64, bool) {
+	for i := len(h) - 1; i >= 0; i-- {
+		if h[i].ts.After(ts) {
+			continue
+		}
+		if ts.Sub(h[i].ts) > maxOffset {
+			break
+		}
+		return h[i].pressure, true
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package weather
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/niktheblak/ruuvitag-gollector/pkg/sensor"
+)
+
+const (
+	backyard = "cc:ca:7e:52:cc:34"
+	upstairs = "fb:e1:b7:04:95:ee"
+)
+
+func TestTrend(t *testing.T) {
+	assert.Equal(t, Rising, Trend(2))
+	assert.Equal(t, Falling, Trend(-1.6))
+	assert.Equal(t, Steady, Trend(0.5))
+}
+
+func TestTendencyCode(t *testing.T) {
+	tests := []struct {
+		p0, p1, p2 float64
+		code       int
+	}{
+		{1000, 1001, 1000.5, 0},
+		{1000, 1002, 1002, 1},
+		{1000, 1001, 1002, 2},
+		{1000, 1000, 1002, 3},
+		{1000, 1000.05, 1000, 4},
+		{1000, 999, 1000, 5},
+		{1000, 998, 998, 6},
+		{1000, 999, 998, 7},
+		{1000, 1000, 998, 8},
+		{1000, 999.5, 997, 8},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.code, TendencyCode(test.p0, test.p1, test.p2), "%v", test)
+	}
+}
+
+func TestZambretti(t *testing.T) {
+	n, forecast := Zambretti(1020, Steady)
+	assert.Equal(t, 11, n)
+	assert.Equal(t, "Fine weather", forecast)
+	n, forecast = Zambretti(990, Falling)
+	assert.Equal(t, 8, n)
+	assert.Equal(t, "Rain at times, becoming very unsettled", forecast)
+	n, _ = Zambretti(900, Rising)
+	assert.Equal(t, 32, n)
+	n, _ = Zambretti(1080, Falling)
+	assert.Equal(t, 1, n)
+}
+
+func TestStation(t *testing.T) {
+	s := &Station{Addrs: map[string]bool{backyard: true}}
+	ts := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	var sd sensor.Data
+	var err error
+	// Pressure falls 3 hPa in 3 hours
+	for m := 0; m <= 180; m++ {
+		sd, err = s.Process(sensor.Data{
+			Addr:      backyard,
+			Pressure:  sensor.Float64(1010 - float64(m)/60),
+			Timestamp: ts.Add(time.Duration(m) * time.Minute),
+		})
+		require.NoError(t, err)
+		if m < 180 {
+			assert.Nil(t, sd.PressureTendency)
+		}
+	}
+	require.NotNil(t, sd.PressureTendency)
+	assert.InDelta(t, -3, *sd.PressureTendency, 0.001)
+	assert.Equal(t, sensor.Int(7), sd.PressureTendencyCode)
+	assert.Equal(t, Falling, sd.PressureTrend)
+	assert.Equal(t, sensor.Int(6), sd.ForecastCode)
+	assert.Equal(t, "Unsettled, rain later", sd.Forecast)
+
+	// Indoor sensors are not processed
+	sd, err = s.Process(sensor.Data{Addr: upstairs, Pressure: sensor.Float64(1000), Timestamp: ts})
+	require.NoError(t, err)
+	assert.Nil(t, sd.PressureTendency)
+}
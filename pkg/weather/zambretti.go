@@ -0,0 +1,69 @@
+package weather
+
+import (
+	"math"
+)
+
+// forecasts contains the Zambretti forecasts by forecast number. Numbers 1-9 are used
+// with falling pressure, 10-19 with steady pressure and 20-32 with rising pressure.
+var forecasts = []string{
+	"",
+	"Settled fine",
+	"Fine weather",
+	"Fine, becoming less settled",
+	"Fairly fine, showery later",
+	"Showery, becoming more unsettled",
+	"Unsettled, rain later",
+	"Rain at times, worse later",
+	"Rain at times, becoming very unsettled",
+	"Very unsettled, rain",
+	"Settled fine",
+	"Fine weather",
+	"Fine, possibly showers",
+	"Fairly fine, showers likely",
+	"Showery, bright intervals",
+	"Changeable, some rain",
+	"Unsettled, rain at times",
+	"Rain at frequent intervals",
+	"Very unsettled, rain",
+	"Stormy, much rain",
+	"Settled fine",
+	"Fine weather",
+	"Becoming fine",
+	"Fairly fine, improving",
+	"Fairly fine, possibly showers early",
+	"Showery early, improving",
+	"Changeable, mending",
+	"Rather unsettled, clearing later",
+	"Unsettled, probably improving",
+	"Unsettled, short fine intervals",
+	"Very unsettled, finer at times",
+	"Stormy, possibly improving",
+	"Stormy, much rain",
+}
+
+// Zambretti returns the Zambretti forecast number and text for the sea level pressure (hPa)
+// and its trend. Wind direction and season adjustments of the original forecaster are not applied.
+func Zambretti(seaLevelPressure float64, trend string) (int, string) {
+	var z float64
+	var lo, hi int
+	switch trend {
+	case Falling:
+		z = 127 - 0.12*seaLevelPressure
+		lo, hi = 1, 9
+	case Rising:
+		z = 185 - 0.16*seaLevelPressure
+		lo, hi = 20, 32
+	default:
+		z = 144 - 0.13*seaLevelPressure
+		lo, hi = 10, 19
+	}
+	n := int(math.Round(z))
+	if n < lo {
+		n = lo
+	}
+	if n > hi {
+		n = hi
+	}
+	return n, forecasts[n]
+}